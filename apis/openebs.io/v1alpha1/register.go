@@ -54,6 +54,32 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&RsyncPopulatorList{},
 		&DataPopulator{},
 		&DataPopulatorList{},
+		&RclonePopulator{},
+		&RclonePopulatorList{},
+		&HTTPPopulator{},
+		&HTTPPopulatorList{},
+		&GitPopulator{},
+		&GitPopulatorList{},
+		&OCIPopulator{},
+		&OCIPopulatorList{},
+		&RestorePopulator{},
+		&RestorePopulatorList{},
+		&ContainerPopulator{},
+		&ContainerPopulatorList{},
+		&NFSPopulator{},
+		&NFSPopulatorList{},
+		&DataExport{},
+		&DataExportList{},
+		&PVCExport{},
+		&PVCExportList{},
+		&DataSync{},
+		&DataSyncList{},
+		&DataReplication{},
+		&DataReplicationList{},
+		&StatefulSetMigration{},
+		&StatefulSetMigrationList{},
+		&DataPopulatorSet{},
+		&DataPopulatorSetList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -19,6 +19,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,6 +28,29 @@ const (
 	StatusInProgress         = "InProgress"
 	StatusCompleted          = "Completed"
 	StatusFailed             = "Failed"
+	StatusReady              = "Ready"
+	StatusSuspended          = "Suspended"
+	StatusReadyForCutover    = "ReadyForCutover"
+	StatusPending            = "Pending"
+	StatusPaused             = "Paused"
+
+	// Phases of a live migration
+	LivePhaseCopying   = "Copying"
+	LivePhaseQuiescing = "Quiescing"
+	LivePhaseFinalSync = "FinalSync"
+
+	// Steps of rebinding the source pvc
+	RebindStepWorkloadsStopped = "WorkloadsStopped"
+	RebindStepVolumesRetained  = "VolumesRetained"
+	RebindStepPVCsDeleted      = "PVCsDeleted"
+	RebindStepVolumeReleased   = "VolumeReleased"
+	RebindStepPVCRecreated     = "PVCRecreated"
+	RebindStepPolicyRestored   = "ReclaimPolicyRestored"
+
+	// Phases of a statefulset migration
+	StatefulSetPhaseScalingDown = "ScalingDown"
+	StatefulSetPhaseMigrating   = "Migrating"
+	StatefulSetPhaseRecreating  = "Recreating"
 )
 
 // RsyncPopulator is a volume populator that helps
@@ -49,17 +73,101 @@ type RsyncPopulatorList struct {
 	Items []RsyncPopulator `json:"items"`
 }
 
-// RsyncPopulatorSpec contains the information of rsync daemon.
+// RsyncPopulatorSpec contains the information of rsync daemon. Either the
+// username, password, path and url or the connection secret must be set.
 type RsyncPopulatorSpec struct {
 	// Username is used as credential to access rsync daemon by the client.
-	Username string `json:"username"`
+	// +optional
+	Username string `json:"username,omitempty"`
 	// Password is used as credential to access rsync daemon by the client.
-	Password string `json:"password"`
+	// +optional
+	Password string `json:"password,omitempty"`
 	// Path represent mount path of the volume which we want to sync by the client.
-	Path string `json:"path"`
+	// +optional
+	Path string `json:"path,omitempty"`
 	// URL is rsync daemon url it can be dns can be ip:port. Client will use
 	// it to connect and get the data from daemon.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// ConnectionSecret is name of the secret, in the namespace of the
+	// populator, having the url, path, username and password keys, like
+	// the connection secret written by a PVCExport. If the secret has the
+	// ca.crt key, the daemon is connected over TLS and its certificate is
+	// verified using it. The keys of the secret take precedence over the
+	// fields of the spec.
+	// +optional
+	ConnectionSecret string `json:"connectionSecret,omitempty"`
+	// BandwidthLimit is the rate in bytes per second, like 10Mi, at which
+	// the data is copied from the daemon. It is not limited if not set.
+	// +optional
+	BandwidthLimit *resource.Quantity `json:"bandwidthLimit,omitempty"`
+	// Options are the rsync behaviours used to copy the data, by default
+	// all of the file metadata is preserved.
+	// +optional
+	Options *RsyncOptions `json:"options,omitempty"`
+	// SubPath is the path inside the volume into which the data is copied.
+	// The data is copied into the root of the volume if it is not set.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+	// Include is a list of rsync filter patterns, only the matching files
+	// are copied if it is set.
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Exclude is a list of rsync filter patterns, matching files are not
+	// copied. Exclude takes precedence over include.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+	// Sources are the rsync daemons whose data is copied one after the
+	// other, instead of the url and path. The username and password are
+	// used for all of them.
+	// +optional
+	Sources []RsyncPopulatorSource `json:"sources,omitempty"`
+}
+
+// RsyncPopulatorSource is an rsync daemon whose data is copied into a
+// directory of the volume
+type RsyncPopulatorSource struct {
+	// URL is rsync daemon url, like ip:port.
 	URL string `json:"url"`
+	// Path is the path on the rsync daemon whose data is copied.
+	Path string `json:"path"`
+	// SubPath is the path inside the volume into which the data is copied.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// RsyncOptions contains the rsync behaviours used to copy the data. The
+// modification times of the files are always preserved, as the files which
+// are already copied are skipped by them when a transfer is resumed.
+type RsyncOptions struct {
+	// Archive preserves the permissions, numeric owner and group, symlinks,
+	// devices and special files. Defaults to true.
+	// +optional
+	Archive *bool `json:"archive,omitempty"`
+	// HardLinks preserves the hard links. Defaults to true.
+	// +optional
+	HardLinks *bool `json:"hardLinks,omitempty"`
+	// ACLs preserves the ACLs, the destination file system must support
+	// them. Defaults to true.
+	// +optional
+	ACLs *bool `json:"acls,omitempty"`
+	// Xattrs preserves the extended attributes, the destination file system
+	// must support them. Defaults to true.
+	// +optional
+	Xattrs *bool `json:"xattrs,omitempty"`
+	// Sparse writes the runs of zeros of the files as holes.
+	// +optional
+	Sparse bool `json:"sparse,omitempty"`
+	// Compress compresses the data sent over the network.
+	// +optional
+	Compress bool `json:"compress,omitempty"`
+	// Checksum compares the files by their checksum instead of their size
+	// and modification time, to find the ones to copy.
+	// +optional
+	Checksum bool `json:"checksum,omitempty"`
+	// Delete deletes the files of the destination which are not in the source.
+	// +optional
+	Delete bool `json:"delete,omitempty"`
 }
 
 // DataPopulator contains information used for populating volume from
@@ -78,18 +186,284 @@ type DataPopulator struct {
 
 // DataPopulatorSpec contains information of the source and target pvc
 type DataPopulatorSpec struct {
-	// SourcePVC is name of the PVC that we want to copy data from
-	SourcePVC string `json:"sourcePVC"`
+	// SourcePVC is name of the PVC that we want to copy data from, it is
+	// not set if the sources are set.
+	// +optional
+	SourcePVC string `json:"sourcePVC,omitempty"`
 	// SourcePVCNamespace is namespace of the PVC that we want to copy
-	SourcePVCNamespace string `json:"sourcePVCNamespace"`
+	// +optional
+	SourcePVCNamespace string `json:"sourcePVCNamespace,omitempty"`
+	// Sources are the PVCs whose data is merged into the destination PVC,
+	// each of them into its own directory. They are set instead of the
+	// source PVC, the destination PVC is then named after the data populator.
+	// +optional
+	Sources []DataPopulatorSource `json:"sources,omitempty"`
 	// DestinationPVC is new PVC name. it will be created in openebs- namespace
 	DestinationPVC corev1.PersistentVolumeClaimSpec `json:"destinationPVC"`
+	// SourceCluster is set when the source PVC is in another cluster. The
+	// rsync daemon is then created in that cluster and exposed over TLS.
+	// +optional
+	SourceCluster *SourceCluster `json:"sourceCluster,omitempty"`
+	// Live migrates the data while the application is running. The data is
+	// copied in passes till the changes are small, then the application is
+	// scaled down for the final pass.
+	// +optional
+	Live *LiveMigration `json:"live,omitempty"`
+	// RebindSourcePVC recreates the source PVC bound to the populated volume
+	// once the data is populated, so that the workloads don't need to be
+	// changed. It is done once no pods are using the source and destination
+	// PVCs, both of their volumes are retained.
+	// +optional
+	RebindSourcePVC bool `json:"rebindSourcePVC,omitempty"`
+	// Priority orders the data populators waiting for the concurrency
+	// limits, the ones with a higher priority are started first.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+	// PriorityClassName is name of the PriorityClass whose value is used
+	// as the priority, if priority is not set.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// TransferWindow is when the data may be copied, it overrides the
+	// transfer window of the operator.
+	// +optional
+	TransferWindow *TransferWindow `json:"transferWindow,omitempty"`
+	// Suspend stops copying the data, the data which is already copied is
+	// kept and only the rest of it is copied once it is unset.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// BandwidthLimit is the rate in bytes per second, like 10Mi, at which
+	// the data is copied. It may be lowered to the share of the data
+	// populator of the aggregate bandwidth limit of the operator.
+	// +optional
+	BandwidthLimit *resource.Quantity `json:"bandwidthLimit,omitempty"`
+	// RsyncOptions are the rsync behaviours used to copy the data, by
+	// default all of the file metadata is preserved.
+	// +optional
+	RsyncOptions *RsyncOptions `json:"rsyncOptions,omitempty"`
+	// SourceSubPath is the path inside the source volume whose contents are
+	// copied. The whole volume is copied if it is not set.
+	// +optional
+	SourceSubPath string `json:"sourceSubPath,omitempty"`
+	// DestinationSubPath is the path inside the destination volume into
+	// which the data is copied. It is copied into the root of the volume if
+	// it is not set.
+	// +optional
+	DestinationSubPath string `json:"destinationSubPath,omitempty"`
+	// Include is a list of rsync filter patterns, only the matching files
+	// are copied if it is set.
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Exclude is a list of rsync filter patterns, matching files are not
+	// copied. Exclude takes precedence over include.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// DataPopulatorSource is a PVC whose data is merged into the destination PVC
+type DataPopulatorSource struct {
+	// PVC is name of the source PVC.
+	PVC string `json:"pvc"`
+	// Namespace is namespace of the source PVC, defaults to sourcePVCNamespace
+	// or the namespace of the data populator.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// SubPath is the path inside the source volume whose contents are
+	// copied. The whole volume is copied if it is not set.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+	// DestinationSubPath is the directory of the destination volume into
+	// which the data is copied, defaults to the name of the PVC.
+	// +optional
+	DestinationSubPath string `json:"destinationSubPath,omitempty"`
+}
+
+// TransferWindow contains the schedule of a window during which the
+// transfers may be started
+type TransferWindow struct {
+	// Start is the cron schedule of when the window opens, like "0 22 * * *".
+	Start string `json:"start"`
+	// End is the cron schedule of when the window closes, like "0 6 * * *".
+	End string `json:"end"`
+	// TimeZone is the time zone of the schedules, like "Europe/Berlin",
+	// it defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// PauseOutsideWindow stops the transfers which are running when the
+	// window closes, they are resumed when it opens again.
+	// +optional
+	PauseOutsideWindow bool `json:"pauseOutsideWindow,omitempty"`
+}
+
+// LiveMigration contains information of when the application is scaled
+// down for the final pass
+type LiveMigration struct {
+	// DeltaThreshold is the size of the changed files copied by a pass,
+	// below which the application is scaled down. Defaults to 100Mi.
+	// +optional
+	DeltaThreshold *resource.Quantity `json:"deltaThreshold,omitempty"`
+	// MaxPasses is the number of passes after which the application is
+	// scaled down, even if the changes are not small. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxPasses int32 `json:"maxPasses,omitempty"`
+}
+
+// SourceCluster contains information to reach the cluster of the source pvc
+type SourceCluster struct {
+	// KubeconfigSecret is name of the secret, in the namespace of the data
+	// populator, having the kubeconfig of the source cluster in the
+	// kubeconfig key.
+	KubeconfigSecret string `json:"kubeconfigSecret"`
+	// ServiceType is the type of the service exposing the rsync daemon in
+	// the source cluster. Defaults to LoadBalancer.
+	// +kubebuilder:validation:Enum=NodePort;LoadBalancer
+	// +optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+	// Address is the hostname or IP address used to reach the service
+	// from this cluster. If it is not set, the address of the load
+	// balancer or of the node running the rsync daemon is used.
+	// +optional
+	Address string `json:"address,omitempty"`
 }
 
 // DataPopulatorStatus contains status of volume copy
 type DataPopulatorStatus struct {
 	State   string `json:"state"`
 	Message string `json:"message"`
+	// Live is the status of a live migration.
+	// +optional
+	Live *LiveMigrationStatus `json:"live,omitempty"`
+	// Rebind is the status of rebinding the source pvc.
+	// +optional
+	Rebind *RebindStatus `json:"rebind,omitempty"`
+	// QueuePosition is the position of the data populator among the ones
+	// waiting for the concurrency limits, while it is Pending.
+	// +optional
+	QueuePosition int32 `json:"queuePosition,omitempty"`
+	// Transfer contains where the data is copied, which the concurrency
+	// limits are checked against.
+	// +optional
+	Transfer *TransferStatus `json:"transfer,omitempty"`
+	// Window is the status of the transfer window.
+	// +optional
+	Window *TransferWindowStatus `json:"window,omitempty"`
+	// Interruptions is the number of times the rsync daemon or the
+	// populator pod was restarted while the data was being copied.
+	// +optional
+	Interruptions int32 `json:"interruptions,omitempty"`
+	// LastInterruptionTime is the time when the last interruption was seen.
+	// +optional
+	LastInterruptionTime *metav1.Time `json:"lastInterruptionTime,omitempty"`
+	// Sources is the progress of copying each of the sources, if they are set.
+	// +optional
+	Sources []DataPopulatorSourceStatus `json:"sources,omitempty"`
+}
+
+// DataPopulatorSourceStatus contains the progress of copying a source PVC
+type DataPopulatorSourceStatus struct {
+	PVC       string `json:"pvc"`
+	Namespace string `json:"namespace"`
+	// State is Pending till the source is being copied, and Completed once
+	// it is copied.
+	State string `json:"state"`
+	// CompletionTime is the time when the source was copied.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// TransferWindowStatus contains whether the transfer window is open
+type TransferWindowStatus struct {
+	Open bool `json:"open"`
+	// NextTransition is the time when the window closes if it is open,
+	// or opens if it is closed.
+	NextTransition metav1.Time `json:"nextTransition"`
+}
+
+// TransferStatus contains the nodes and storage class of a transfer
+type TransferStatus struct {
+	// SourceNode is the node of the source volume, if it is known.
+	// +optional
+	SourceNode string `json:"sourceNode,omitempty"`
+	// DestinationNode is the node of the destination volume, if it is known.
+	// +optional
+	DestinationNode string `json:"destinationNode,omitempty"`
+	// StorageClass is the storage class of the destination volume.
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+	// Priority is the priority of the data populator, the ones with a
+	// higher priority are started first.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// StartTime is the time when the transfer was started, it is not set
+	// while the data populator is Pending.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// DaemonPodUID is the uid of the rsync daemon pod which was last seen.
+	// +optional
+	DaemonPodUID string `json:"daemonPodUID,omitempty"`
+	// PopulatorPodUID is the uid of the populator pod which was last seen.
+	// +optional
+	PopulatorPodUID string `json:"populatorPodUID,omitempty"`
+	// BandwidthLimit is the rate in bytes per second at which the data is
+	// copied, it is 0 if it is not limited.
+	// +optional
+	BandwidthLimit int64 `json:"bandwidthLimit,omitempty"`
+}
+
+// RebindStatus contains status of rebinding the source pvc to the populated volume
+type RebindStatus struct {
+	// State is InProgress, Completed or Failed.
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// SourcePV is name of the volume of the source pvc, which is retained.
+	// +optional
+	SourcePV string `json:"sourcePV,omitempty"`
+	// PopulatedPV is name of the populated volume.
+	// +optional
+	PopulatedPV string `json:"populatedPV,omitempty"`
+	// PopulatedPVReclaimPolicy is the reclaim policy of the populated
+	// volume, which is restored once the source pvc is bound to it.
+	// +optional
+	PopulatedPVReclaimPolicy corev1.PersistentVolumeReclaimPolicy `json:"populatedPVReclaimPolicy,omitempty"`
+	// SourcePVCLabels are the labels of the source pvc, which are set
+	// on the recreated pvc.
+	// +optional
+	SourcePVCLabels map[string]string `json:"sourcePVCLabels,omitempty"`
+	// Steps are the completed steps.
+	// +optional
+	Steps []RebindStep `json:"steps,omitempty"`
+}
+
+// RebindStep is a completed step of rebinding the source pvc
+type RebindStep struct {
+	Name    string      `json:"name"`
+	Time    metav1.Time `json:"time"`
+	Message string      `json:"message"`
+}
+
+// LiveMigrationStatus contains status of the passes of a live migration
+type LiveMigrationStatus struct {
+	// Phase is Copying, Quiescing or FinalSync.
+	Phase string `json:"phase"`
+	// Passes is the number of completed passes.
+	Passes int32 `json:"passes"`
+	// LastPassBytes is the size of the changed files copied by the last pass.
+	LastPassBytes int64 `json:"lastPassBytes"`
+	// ActiveJob is name of the job of the running pass.
+	// +optional
+	ActiveJob string `json:"activeJob,omitempty"`
+	// Workloads are the workloads which were scaled down, with their
+	// replicas before it.
+	// +optional
+	Workloads []ScaledWorkload `json:"workloads,omitempty"`
+}
+
+// ScaledWorkload is a workload scaled down by a live migration
+type ScaledWorkload struct {
+	// Kind is Deployment or StatefulSet.
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
 }
 
 // DataPopulatorList is a list of DataPopulator objects
@@ -100,3 +474,909 @@ type DataPopulatorList struct {
 	// List of VolumeCopies
 	Items []DataPopulator `json:"items"`
 }
+
+// RclonePopulator is a volume populator that helps to create
+// a volume from a S3 compatible object storage bucket.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RclonePopulator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RclonePopulatorSpec `json:"spec"`
+}
+
+// RclonePopulatorList is a list of RclonePopulator objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RclonePopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []RclonePopulator `json:"items"`
+}
+
+// RclonePopulatorSpec contains the information of the bucket to copy from.
+type RclonePopulatorSpec struct {
+	// Bucket is the name of the bucket which we want to copy into the volume.
+	Bucket string `json:"bucket"`
+	// Prefix limits the copy to the objects under this prefix of the bucket.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Endpoint is the url of the object storage, it must be set for
+	// anything other than AWS S3. Eg: http://minio.default:9000
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Provider is the rclone S3 provider of the object storage like
+	// AWS, Minio or Ceph. Defaults to Other.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+	// Region of the bucket.
+	// +optional
+	Region string `json:"region,omitempty"`
+	// CredentialsSecret is name of the secret, in the namespace of the
+	// populator, having AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys.
+	// Rclone uses the credentials from the environment if it is not set.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// Include is a list of rclone filter patterns, only the matching
+	// objects are copied if it is set.
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Exclude is a list of rclone filter patterns, matching objects are
+	// not copied. Exclude takes precedence over include.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// HTTPPopulator is a volume populator that helps to create a volume
+// from an archive served over http or https.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type HTTPPopulator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HTTPPopulatorSpec `json:"spec"`
+}
+
+// HTTPPopulatorList is a list of HTTPPopulator objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type HTTPPopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []HTTPPopulator `json:"items"`
+}
+
+// HTTPPopulatorSpec contains the information of the archive to download.
+type HTTPPopulatorSpec struct {
+	// URL of the archive which we want to extract into the volume.
+	URL string `json:"url"`
+	// SHA256 is the hex encoded sha256 checksum of the archive. The
+	// archive is not extracted if the checksum does not match.
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{64}$`
+	SHA256 string `json:"sha256"`
+	// Format of the archive, it is detected from the url if not set.
+	// +kubebuilder:validation:Enum=tar;tar.gz;tar.zst;zip
+	// +optional
+	Format string `json:"format,omitempty"`
+	// HeadersSecret is name of the secret, in the namespace of the
+	// populator, every key of which is sent as a http header along
+	// with its value. Eg: Authorization
+	// +optional
+	HeadersSecret string `json:"headersSecret,omitempty"`
+	// Retries is the number of times the download is retried on
+	// transient http errors. Defaults to 5.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries *int32 `json:"retries,omitempty"`
+}
+
+// GitPopulator is a volume populator that helps to create
+// a volume from a commit of a git repository.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type GitPopulator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GitPopulatorSpec `json:"spec"`
+}
+
+// GitPopulatorList is a list of GitPopulator objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type GitPopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []GitPopulator `json:"items"`
+}
+
+// GitPopulatorSpec contains the information of the git repository.
+type GitPopulatorSpec struct {
+	// Repository is the url of the git repository to clone. It can be a
+	// https, ssh, git or file url. Eg: https://github.com/openebs/data-populator.git
+	Repository string `json:"repository"`
+	// Ref is the branch or tag to checkout. The default branch of the
+	// repository is used if neither ref nor commit is set.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+	// Commit is the commit to checkout. If ref is also set, the commit
+	// must be reachable from the ref within the given depth.
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{7,40}$`
+	// +optional
+	Commit string `json:"commit,omitempty"`
+	// Depth creates a shallow clone with the given number of commits.
+	// The full history is cloned if it is not set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Depth *int32 `json:"depth,omitempty"`
+	// Submodules when true also checks out the submodules recursively.
+	// +optional
+	Submodules bool `json:"submodules,omitempty"`
+	// SparseCheckoutPaths limits the checkout to the given paths of
+	// the repository. Eg: models/
+	// +optional
+	SparseCheckoutPaths []string `json:"sparseCheckoutPaths,omitempty"`
+	// CredentialsSecret is name of the secret, in the namespace of the
+	// populator, used to access the repository. It can have the username
+	// and password keys for https, or the ssh-privatekey and optionally
+	// the known_hosts keys for ssh.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// OCIPopulator is a volume populator that helps to create a volume
+// from an image or artifact stored in an OCI registry.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type OCIPopulator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OCIPopulatorSpec `json:"spec"`
+}
+
+// OCIPopulatorList is a list of OCIPopulator objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type OCIPopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []OCIPopulator `json:"items"`
+}
+
+// OCIPopulatorSpec contains the information of the image or artifact.
+type OCIPopulatorSpec struct {
+	// Repository of the image or artifact without any tag or digest.
+	// Eg: registry.example.com/models/resnet
+	Repository string `json:"repository"`
+	// Digest of the image or artifact manifest to pull. The manifest is
+	// verified against it, and it must not be the digest of an index.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	Digest string `json:"digest"`
+	// Layers is a list of digests of the layers to extract. All the
+	// layers are extracted if it is not set. For images without selected
+	// layers the flattened filesystem of the image is extracted.
+	// +optional
+	Layers []string `json:"layers,omitempty"`
+	// Paths limits the extraction to the given paths of the layers.
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+	// CredentialsSecret is name of the kubernetes.io/dockerconfigjson
+	// secret, in the namespace of the populator, used to pull from the
+	// registry.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// Insecure allows pulling from a registry over http or with a
+	// certificate which can not be verified.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+const (
+	// RestoreEngineRestic restores from a restic repository
+	RestoreEngineRestic = "restic"
+	// RestoreEngineKopia restores from a kopia repository
+	RestoreEngineKopia = "kopia"
+)
+
+// RestorePopulator is a volume populator that helps to create a volume
+// by restoring a snapshot from a restic or kopia backup repository.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RestorePopulator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RestorePopulatorSpec `json:"spec"`
+}
+
+// RestorePopulatorList is a list of RestorePopulator objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RestorePopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []RestorePopulator `json:"items"`
+}
+
+// RestorePopulatorSpec contains the information of the backup repository
+// and the snapshot to restore.
+type RestorePopulatorSpec struct {
+	// Engine is the backup tool which created the repository.
+	// Defaults to restic.
+	// +kubebuilder:validation:Enum=restic;kopia
+	// +optional
+	Engine string `json:"engine,omitempty"`
+	// Repository is the location of the repository. For restic it is the
+	// repository as given to restic -r. Eg: s3:http://minio.default:9000/backups
+	// For kopia it is s3:<endpoint>/<bucket>[/<prefix>], it is not needed
+	// if the password secret has a kopia connection token.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+	// SnapshotID is the id of the snapshot to restore. If it is not set,
+	// the latest snapshot matching the tags, host and path is restored.
+	// +optional
+	SnapshotID string `json:"snapshotID,omitempty"`
+	// Tags to match the snapshot. For kopia the tags are key:value.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// Host to match the snapshot.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Path is the backed up path to match the snapshot. The contents of
+	// the path are restored at the root of the volume.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// PasswordSecret is name of the secret, in the namespace of the
+	// populator, having the repository password in the password key.
+	// For kopia it can have a repository connection token in the
+	// token key instead of the repository.
+	PasswordSecret string `json:"passwordSecret"`
+	// CredentialsSecret is name of the secret, in the namespace of the
+	// populator, every key of which is set as an environment variable
+	// for the backend of the repository. Eg: AWS_ACCESS_KEY_ID
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// ContainerPopulator is a volume populator that helps to create a volume
+// by running a user supplied container with the volume mounted at /mnt.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ContainerPopulator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ContainerPopulatorSpec `json:"spec"`
+}
+
+// ContainerPopulatorList is a list of ContainerPopulator objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ContainerPopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ContainerPopulator `json:"items"`
+}
+
+// ContainerPopulatorSpec is a restricted container template. The container
+// can not be privileged, it runs with the runtime default seccomp profile
+// and only the capabilities needed to write files.
+type ContainerPopulatorSpec struct {
+	// Image of the container.
+	Image string `json:"image"`
+	// Command is the entrypoint of the container. The entrypoint of
+	// the image is used if it is not set.
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Args to the entrypoint.
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Env is the list of environment variables of the container.
+	// +optional
+	Env []ContainerPopulatorEnvVar `json:"env,omitempty"`
+	// EnvFrom is the list of secrets, in the namespace of the populator,
+	// every key of which is set as an environment variable.
+	// +optional
+	EnvFrom []ContainerPopulatorEnvFromSource `json:"envFrom,omitempty"`
+	// Resources of the container.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ContainerPopulatorEnvVar is an environment variable having either a
+// value or a value from a key of a secret.
+type ContainerPopulatorEnvVar struct {
+	// Name of the environment variable.
+	Name string `json:"name"`
+	// Value of the environment variable.
+	// +optional
+	Value string `json:"value,omitempty"`
+	// SecretKeyRef selects a key of a secret, in the namespace of the
+	// populator, as the value.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// ContainerPopulatorEnvFromSource is a secret whose keys are set as
+// environment variables.
+type ContainerPopulatorEnvFromSource struct {
+	// Prefix added to the name of every environment variable.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// SecretRef is the secret in the namespace of the populator.
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// NFSPopulator is a volume populator that helps to create a volume
+// from a path of an NFS export.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type NFSPopulator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NFSPopulatorSpec `json:"spec"`
+}
+
+// NFSPopulatorList is a list of NFSPopulator objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type NFSPopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NFSPopulator `json:"items"`
+}
+
+// NFSPopulatorSpec contains the information of the NFS export. The export
+// is mounted read-only by the kubelet, so the nodes need the NFS client.
+type NFSPopulatorSpec struct {
+	// Server is the hostname or IP address of the NFS server.
+	Server string `json:"server"`
+	// Path is the path exported by the NFS server. Eg: /exports
+	Path string `json:"path"`
+	// SubPath is the path inside the export whose contents are copied.
+	// The whole export is copied if it is not set.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+	// Options are the rsync behaviours used to copy the data, by default
+	// all of the file metadata is preserved.
+	// +optional
+	Options *RsyncOptions `json:"options,omitempty"`
+}
+
+// DataExport contains information used for exporting a volume
+// to a target outside the cluster.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec contains details of the source pvc and the target of the export.
+	Spec DataExportSpec `json:"spec"`
+	// +optional
+	Status DataExportStatus `json:"status"`
+}
+
+// DataExportSpec contains information of the source pvc and the target
+type DataExportSpec struct {
+	// SourcePVC is name of the PVC, in the namespace of the export,
+	// that we want to export. It is mounted read-only by the export job.
+	SourcePVC string `json:"sourcePVC"`
+	// Target is where the data is exported to.
+	Target DataExportTarget `json:"target"`
+}
+
+// DataExportTarget is the target of an export, exactly one of
+// the targets must be set.
+type DataExportTarget struct {
+	// Rsync exports to a rsync daemon.
+	// +optional
+	Rsync *RsyncExportTarget `json:"rsync,omitempty"`
+	// SSH exports to a host over ssh using rsync.
+	// +optional
+	SSH *SSHExportTarget `json:"ssh,omitempty"`
+	// S3 exports to a S3 compatible object storage bucket using rclone.
+	// +optional
+	S3 *S3ExportTarget `json:"s3,omitempty"`
+}
+
+// RsyncExportTarget contains the information of rsync daemon.
+type RsyncExportTarget struct {
+	// URL is rsync daemon url it can be dns can be ip:port.
+	URL string `json:"url"`
+	// Path is the module of the daemon, followed by the path inside the
+	// module, where the data is copied. Eg: /data/backups
+	Path string `json:"path"`
+	// Username is used as credential to access rsync daemon.
+	// +optional
+	Username string `json:"username,omitempty"`
+	// PasswordSecret is name of the secret, in the namespace of the
+	// export, having the password of the user in the password key.
+	// +optional
+	PasswordSecret string `json:"passwordSecret,omitempty"`
+	// Options are the rsync behaviours used to copy the data, by default
+	// all of the file metadata is preserved.
+	// +optional
+	Options *RsyncOptions `json:"options,omitempty"`
+}
+
+// SSHExportTarget contains the information of the ssh host.
+type SSHExportTarget struct {
+	// Host is the hostname or IP address of the ssh server.
+	Host string `json:"host"`
+	// Port of the ssh server. Defaults to 22.
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+	// User to login as.
+	User string `json:"user"`
+	// Path on the host where the data is copied.
+	Path string `json:"path"`
+	// CredentialsSecret is name of the kubernetes.io/ssh-auth secret, in
+	// the namespace of the export, having the ssh-privatekey key. The host
+	// key is verified with the known_hosts key, which is required unless
+	// InsecureAcceptNewHostKey is set.
+	CredentialsSecret string `json:"credentialsSecret"`
+	// InsecureAcceptNewHostKey accepts the host key on first use when the
+	// known_hosts key is not in the credentials secret. This is prone to
+	// man-in-the-middle attacks and must only be used on trusted networks.
+	// +optional
+	InsecureAcceptNewHostKey bool `json:"insecureAcceptNewHostKey,omitempty"`
+	// Options are the rsync behaviours used to copy the data, by default
+	// all of the file metadata is preserved.
+	// +optional
+	Options *RsyncOptions `json:"options,omitempty"`
+}
+
+// S3ExportTarget contains the information of the bucket.
+type S3ExportTarget struct {
+	// Bucket is the name of the bucket where the data is copied.
+	Bucket string `json:"bucket"`
+	// Prefix is the prefix of the bucket under which the data is copied.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Endpoint is the url of the object storage, it must be set for
+	// anything other than AWS S3. Eg: http://minio.default:9000
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Provider is the rclone S3 provider of the object storage like
+	// AWS, Minio or Ceph. Defaults to Other.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+	// Region of the bucket.
+	// +optional
+	Region string `json:"region,omitempty"`
+	// CredentialsSecret is name of the secret, in the namespace of the
+	// export, having AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys.
+	// Rclone uses the credentials from the environment if it is not set.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// DataExportStatus contains status of the export
+type DataExportStatus struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// StartTime is the time when the export job was created.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the export was completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// DataExportList is a list of DataExport objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DataExport `json:"items"`
+}
+
+// PVCExport exposes a volume as an authenticated rsync source outside
+// the cluster, which can be used by a RsyncPopulator in another cluster.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PVCExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec contains details of the source pvc and how it is exposed.
+	Spec PVCExportSpec `json:"spec"`
+	// +optional
+	Status PVCExportStatus `json:"status"`
+}
+
+// PVCExportSpec contains information of the source pvc and the service
+type PVCExportSpec struct {
+	// SourcePVC is name of the PVC, in the namespace of the export,
+	// that we want to expose. It is exposed read-only.
+	SourcePVC string `json:"sourcePVC"`
+	// ServiceType is the type of the service exposing the rsync daemon.
+	// Defaults to LoadBalancer.
+	// +kubebuilder:validation:Enum=NodePort;LoadBalancer
+	// +optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+	// Address is the hostname or IP address used to reach the service
+	// from outside the cluster. If it is not set, the address of the load
+	// balancer or of the node running the rsync daemon is used.
+	// +optional
+	Address string `json:"address,omitempty"`
+	// TLS wraps the rsync daemon with TLS using a certificate signed by a
+	// CA generated for the export, the CA is added to the connection secret.
+	// Defaults to true.
+	// +optional
+	TLS *bool `json:"tls,omitempty"`
+	// AllowedSourceRanges are the CIDRs of the clients which can connect to
+	// the rsync daemon. They are set as the hosts allowed by the daemon and
+	// as the source ranges of a LoadBalancer service. With TLS, the daemon
+	// only sees the connections from stunnel, so they can only be enforced
+	// on a LoadBalancer service. The external traffic policy of a NodePort or
+	// LoadBalancer service is set to Local, so that the daemon sees the
+	// address of the client. Every client is allowed if they are not set.
+	// +optional
+	AllowedSourceRanges []string `json:"allowedSourceRanges,omitempty"`
+	// ConnectionSecret is name of the secret, in the namespace of the
+	// export, where the connection details are written. Defaults to
+	// <name>-connection.
+	// +optional
+	ConnectionSecret string `json:"connectionSecret,omitempty"`
+}
+
+// PVCExportStatus contains status of the export
+type PVCExportStatus struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// URL is the address and port of the rsync daemon.
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// PVCExportList is a list of PVCExport objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type PVCExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PVCExport `json:"items"`
+}
+
+// DataSync copies the data of a volume back into another existing volume,
+// like the original volume of a migration, to roll back the changes.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataSync struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec contains details of the source and destination pvc.
+	Spec DataSyncSpec `json:"spec"`
+	// +optional
+	Status DataSyncStatus `json:"status"`
+}
+
+// DataSyncSpec contains information of the source and destination pvc
+type DataSyncSpec struct {
+	// SourcePVC is name of the PVC that we want to copy data from. It is
+	// mounted read-only.
+	SourcePVC string `json:"sourcePVC"`
+	// SourcePVCNamespace is the namespace of the source PVC, like that of
+	// the destination PVC of a DataPopulator having a source PVC in
+	// another namespace. Defaults to the namespace of the sync.
+	// +optional
+	SourcePVCNamespace string `json:"sourcePVCNamespace,omitempty"`
+	// DestinationPVC is name of the existing PVC, in the namespace of the
+	// sync, that the data is written into. The files of the destination
+	// which are not in the source are deleted.
+	DestinationPVC string `json:"destinationPVC"`
+	// ConfirmOverwrite must be set to the name of the destination PVC, to
+	// confirm that its data can be overwritten.
+	ConfirmOverwrite string `json:"confirmOverwrite"`
+}
+
+// DataSyncStatus contains status of the sync
+type DataSyncStatus struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// StartTime is the time when the sync job was created.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the sync was completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// DataSyncList is a list of DataSync objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataSyncList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DataSync `json:"items"`
+}
+
+// DataReplication periodically copies the changes of a volume into an
+// existing volume, to keep a warm standby copy of it.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataReplication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec contains details of the source and destination pvc and the schedule.
+	Spec DataReplicationSpec `json:"spec"`
+	// +optional
+	Status DataReplicationStatus `json:"status"`
+}
+
+// DataReplicationSpec contains information of the source and destination
+// pvc and when the data is replicated
+type DataReplicationSpec struct {
+	// SourcePVC is name of the PVC, in the namespace of the replication,
+	// that we want to replicate. It is mounted read-only.
+	SourcePVC string `json:"sourcePVC"`
+	// DestinationPVC is name of the existing PVC, in the namespace of the
+	// replication, that the data is written into. The files of the
+	// destination which are not in the source are deleted.
+	DestinationPVC string `json:"destinationPVC"`
+	// Schedule is the cron schedule of the syncs, like "*/15 * * * *".
+	Schedule string `json:"schedule"`
+	// Suspend pauses the replication, no new syncs are started while it is
+	// set. A sync which is already running is completed.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// RsyncOptions are the rsync behaviours used to copy the data, by
+	// default all of the file metadata is preserved. The files which are
+	// not in the source are always deleted.
+	// +optional
+	RsyncOptions *RsyncOptions `json:"rsyncOptions,omitempty"`
+}
+
+// DataReplicationStatus contains status of the replication
+type DataReplicationStatus struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// Active is name of the job of the running sync.
+	// +optional
+	Active string `json:"active,omitempty"`
+	// LastScheduleTime is the time when the last sync was started.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulSyncTime is the start time of the last successful
+	// sync, the destination has the data of the source as of this time.
+	// +optional
+	LastSuccessfulSyncTime *metav1.Time `json:"lastSuccessfulSyncTime,omitempty"`
+	// Lag is how old the data of the destination was when the last
+	// successful sync completed, that is the time since the start of the
+	// successful sync before it.
+	// +optional
+	Lag *metav1.Duration `json:"lag,omitempty"`
+	// Syncs are the last few syncs, latest first.
+	// +optional
+	Syncs []DataReplicationSync `json:"syncs,omitempty"`
+}
+
+// DataReplicationSync contains the result of a sync
+type DataReplicationSync struct {
+	// Job is name of the job of the sync.
+	Job string `json:"job"`
+	// State is Completed or Failed.
+	State     string      `json:"state"`
+	StartTime metav1.Time `json:"startTime"`
+	// CompletionTime is the time when the job completed or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// BytesTransferred is the size of the changed files that were copied.
+	// +optional
+	BytesTransferred int64 `json:"bytesTransferred,omitempty"`
+}
+
+// DataReplicationList is a list of DataReplication objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataReplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DataReplication `json:"items"`
+}
+
+// StatefulSetMigration migrates all the volumes of a statefulset into
+// another storage class, and recreates the statefulset using it.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type StatefulSetMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec contains details of the statefulset and the storage class.
+	Spec StatefulSetMigrationSpec `json:"spec"`
+	// +optional
+	Status StatefulSetMigrationStatus `json:"status"`
+}
+
+// StatefulSetMigrationSpec contains information of the statefulset and
+// the storage class its volumes are migrated into
+type StatefulSetMigrationSpec struct {
+	// StatefulSet is name of the statefulset, in the namespace of the
+	// migration, whose volumes are migrated.
+	StatefulSet string `json:"statefulSet"`
+	// StorageClassName is name of the storage class the volumes are
+	// migrated into.
+	StorageClassName string `json:"storageClassName"`
+	// VolumeClaimTemplates are names of the volume claim templates whose
+	// volumes are migrated, all of them are migrated if it is not set.
+	// +optional
+	VolumeClaimTemplates []string `json:"volumeClaimTemplates,omitempty"`
+}
+
+// StatefulSetMigrationStatus contains status of the migration
+type StatefulSetMigrationStatus struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// Phase is ScalingDown, Migrating or Recreating.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// Replicas is the number of replicas of the statefulset, which it is
+	// scaled back to when it is recreated.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// TotalVolumes is the number of volumes being migrated.
+	// +optional
+	TotalVolumes int32 `json:"totalVolumes,omitempty"`
+	// MigratedVolumes is the number of volumes which are populated and
+	// whose pvcs are rebound to the populated volumes.
+	// +optional
+	MigratedVolumes int32 `json:"migratedVolumes,omitempty"`
+	// Volumes are the volumes being migrated.
+	// +optional
+	Volumes []StatefulSetMigrationVolume `json:"volumes,omitempty"`
+	// StartTime is the time when the migration was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the statefulset was recreated.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// StatefulSetMigrationVolume contains status of the migration of a volume
+type StatefulSetMigrationVolume struct {
+	// PVC is name of the pvc of the replica.
+	PVC string `json:"pvc"`
+	// VolumeClaimTemplate is name of the volume claim template of the pvc.
+	VolumeClaimTemplate string `json:"volumeClaimTemplate"`
+	// Ordinal is the ordinal of the replica.
+	Ordinal int32 `json:"ordinal"`
+	// Node is the node where the replica was running, the populated
+	// volume is created on it if its storage class waits for a consumer.
+	// +optional
+	Node string `json:"node,omitempty"`
+	// DataPopulator is name of the data populator of the volume.
+	DataPopulator string `json:"dataPopulator"`
+	// State is the state of the data populator.
+	// +optional
+	State string `json:"state,omitempty"`
+	// RebindState is the state of rebinding the pvc to the populated volume.
+	// +optional
+	RebindState string `json:"rebindState,omitempty"`
+}
+
+// StatefulSetMigrationList is a list of StatefulSetMigration objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type StatefulSetMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StatefulSetMigration `json:"items"`
+}
+
+// DataPopulatorSet creates a data populator for every pvc selected by its
+// labels, to migrate many volumes at once.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataPopulatorSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec contains details of the selected pvcs and the data populators.
+	Spec DataPopulatorSetSpec `json:"spec"`
+	// +optional
+	Status DataPopulatorSetStatus `json:"status"`
+}
+
+// DataPopulatorSetSpec contains information of the pvcs to populate and
+// the template of their data populators
+type DataPopulatorSetSpec struct {
+	// SourcePVCNamespace is the namespace of the pvcs, it defaults to the
+	// namespace of the set.
+	// +optional
+	SourcePVCNamespace string `json:"sourcePVCNamespace,omitempty"`
+	// Selector selects the pvcs by their labels, all the pvcs of the
+	// namespace are selected if it is not set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Template is the template of the data populators.
+	Template DataPopulatorTemplate `json:"template"`
+	// MaxConcurrent is the number of data populators which are run at a
+	// time, all of them are run at once if it is not set.
+	// +optional
+	MaxConcurrent int32 `json:"maxConcurrent,omitempty"`
+}
+
+// DataPopulatorTemplate contains information of the data populators of a set
+type DataPopulatorTemplate struct {
+	// DestinationPVC is the spec of the destination pvcs. The access modes,
+	// size and volume mode which are not set are taken from the source pvc.
+	// $(PVC_NAME), $(PVC_NAMESPACE) and $(STORAGE_CLASS) in the storage
+	// class name are replaced with the name, namespace and storage class
+	// of the source pvc.
+	DestinationPVC corev1.PersistentVolumeClaimSpec `json:"destinationPVC"`
+	// RebindSourcePVC recreates the source PVCs bound to the populated volumes.
+	// +optional
+	RebindSourcePVC bool `json:"rebindSourcePVC,omitempty"`
+	// Priority is the priority of the data populators.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+	// PriorityClassName is name of the PriorityClass of the data populators.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// BandwidthLimit is the bandwidth limit of each of the data populators.
+	// +optional
+	BandwidthLimit *resource.Quantity `json:"bandwidthLimit,omitempty"`
+	// RsyncOptions are the rsync behaviours of the data populators.
+	// +optional
+	RsyncOptions *RsyncOptions `json:"rsyncOptions,omitempty"`
+	// SourceSubPath is the path inside the source volumes which is copied.
+	// +optional
+	SourceSubPath string `json:"sourceSubPath,omitempty"`
+	// DestinationSubPath is the path inside the destination volumes into
+	// which the data is copied.
+	// +optional
+	DestinationSubPath string `json:"destinationSubPath,omitempty"`
+	// Include is a list of rsync filter patterns of the files which are copied.
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Exclude is a list of rsync filter patterns of the files which are not copied.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// DataPopulatorSetStatus contains status of the data populators of the set
+type DataPopulatorSetStatus struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// Succeeded is the number of completed data populators.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+	// Failed is the number of failed data populators.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+	// Running is the number of data populators which are not finished.
+	// +optional
+	Running int32 `json:"running,omitempty"`
+	// Pending is the number of selected pvcs whose data populators are not
+	// created yet.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+	// DataPopulators are the data populators of the selected pvcs.
+	// +optional
+	DataPopulators []DataPopulatorSetMember `json:"dataPopulators,omitempty"`
+}
+
+// DataPopulatorSetMember contains status of the data populator of a pvc
+type DataPopulatorSetMember struct {
+	// PVC is name of the selected pvc.
+	PVC string `json:"pvc"`
+	// DataPopulator is name of the data populator of the pvc.
+	DataPopulator string `json:"dataPopulator"`
+	// State is the state of the data populator, it is not set till the
+	// data populator is created.
+	// +optional
+	State string `json:"state,omitempty"`
+}
+
+// DataPopulatorSetList is a list of DataPopulatorSet objects
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DataPopulatorSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DataPopulatorSet `json:"items"`
+}
@@ -5,30 +5,1467 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerPopulator) DeepCopyInto(out *ContainerPopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerPopulator.
+func (in *ContainerPopulator) DeepCopy() *ContainerPopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerPopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ContainerPopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerPopulatorEnvFromSource) DeepCopyInto(out *ContainerPopulatorEnvFromSource) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerPopulatorEnvFromSource.
+func (in *ContainerPopulatorEnvFromSource) DeepCopy() *ContainerPopulatorEnvFromSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerPopulatorEnvFromSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerPopulatorEnvVar) DeepCopyInto(out *ContainerPopulatorEnvVar) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerPopulatorEnvVar.
+func (in *ContainerPopulatorEnvVar) DeepCopy() *ContainerPopulatorEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerPopulatorEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerPopulatorList) DeepCopyInto(out *ContainerPopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ContainerPopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerPopulatorList.
+func (in *ContainerPopulatorList) DeepCopy() *ContainerPopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerPopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ContainerPopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerPopulatorSpec) DeepCopyInto(out *ContainerPopulatorSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]ContainerPopulatorEnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]ContainerPopulatorEnvFromSource, len(*in))
+		copy(*out, *in)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerPopulatorSpec.
+func (in *ContainerPopulatorSpec) DeepCopy() *ContainerPopulatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerPopulatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExport) DeepCopyInto(out *DataExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExport.
+func (in *DataExport) DeepCopy() *DataExport {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportList) DeepCopyInto(out *DataExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportList.
+func (in *DataExportList) DeepCopy() *DataExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportSpec) DeepCopyInto(out *DataExportSpec) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportSpec.
+func (in *DataExportSpec) DeepCopy() *DataExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportStatus) DeepCopyInto(out *DataExportStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportStatus.
+func (in *DataExportStatus) DeepCopy() *DataExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportTarget) DeepCopyInto(out *DataExportTarget) {
+	*out = *in
+	if in.Rsync != nil {
+		in, out := &in.Rsync, &out.Rsync
+		*out = new(RsyncExportTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(SSHExportTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3ExportTarget)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportTarget.
+func (in *DataExportTarget) DeepCopy() *DataExportTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataPopulator) DeepCopyInto(out *DataPopulator) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulator.
+func (in *DataPopulator) DeepCopy() *DataPopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataPopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorList) DeepCopyInto(out *DataPopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataPopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorList.
+func (in *DataPopulatorList) DeepCopy() *DataPopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataPopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSet) DeepCopyInto(out *DataPopulatorSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSet.
+func (in *DataPopulatorSet) DeepCopy() *DataPopulatorSet {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataPopulatorSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSetList) DeepCopyInto(out *DataPopulatorSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataPopulatorSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSetList.
+func (in *DataPopulatorSetList) DeepCopy() *DataPopulatorSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataPopulatorSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSetMember) DeepCopyInto(out *DataPopulatorSetMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSetMember.
+func (in *DataPopulatorSetMember) DeepCopy() *DataPopulatorSetMember {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSetMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSetSpec) DeepCopyInto(out *DataPopulatorSetSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSetSpec.
+func (in *DataPopulatorSetSpec) DeepCopy() *DataPopulatorSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSetStatus) DeepCopyInto(out *DataPopulatorSetStatus) {
+	*out = *in
+	if in.DataPopulators != nil {
+		in, out := &in.DataPopulators, &out.DataPopulators
+		*out = make([]DataPopulatorSetMember, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSetStatus.
+func (in *DataPopulatorSetStatus) DeepCopy() *DataPopulatorSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSource) DeepCopyInto(out *DataPopulatorSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSource.
+func (in *DataPopulatorSource) DeepCopy() *DataPopulatorSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSourceStatus) DeepCopyInto(out *DataPopulatorSourceStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSourceStatus.
+func (in *DataPopulatorSourceStatus) DeepCopy() *DataPopulatorSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorSpec) DeepCopyInto(out *DataPopulatorSpec) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]DataPopulatorSource, len(*in))
+		copy(*out, *in)
+	}
+	in.DestinationPVC.DeepCopyInto(&out.DestinationPVC)
+	if in.SourceCluster != nil {
+		in, out := &in.SourceCluster, &out.SourceCluster
+		*out = new(SourceCluster)
+		**out = **in
+	}
+	if in.Live != nil {
+		in, out := &in.Live, &out.Live
+		*out = new(LiveMigration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TransferWindow != nil {
+		in, out := &in.TransferWindow, &out.TransferWindow
+		*out = new(TransferWindow)
+		**out = **in
+	}
+	if in.BandwidthLimit != nil {
+		in, out := &in.BandwidthLimit, &out.BandwidthLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RsyncOptions != nil {
+		in, out := &in.RsyncOptions, &out.RsyncOptions
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSpec.
+func (in *DataPopulatorSpec) DeepCopy() *DataPopulatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorStatus) DeepCopyInto(out *DataPopulatorStatus) {
+	*out = *in
+	if in.Live != nil {
+		in, out := &in.Live, &out.Live
+		*out = new(LiveMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rebind != nil {
+		in, out := &in.Rebind, &out.Rebind
+		*out = new(RebindStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transfer != nil {
+		in, out := &in.Transfer, &out.Transfer
+		*out = new(TransferStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(TransferWindowStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastInterruptionTime != nil {
+		in, out := &in.LastInterruptionTime, &out.LastInterruptionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]DataPopulatorSourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorStatus.
+func (in *DataPopulatorStatus) DeepCopy() *DataPopulatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPopulatorTemplate) DeepCopyInto(out *DataPopulatorTemplate) {
+	*out = *in
+	in.DestinationPVC.DeepCopyInto(&out.DestinationPVC)
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BandwidthLimit != nil {
+		in, out := &in.BandwidthLimit, &out.BandwidthLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RsyncOptions != nil {
+		in, out := &in.RsyncOptions, &out.RsyncOptions
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorTemplate.
+func (in *DataPopulatorTemplate) DeepCopy() *DataPopulatorTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPopulatorTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataReplication) DeepCopyInto(out *DataReplication) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataReplication.
+func (in *DataReplication) DeepCopy() *DataReplication {
+	if in == nil {
+		return nil
+	}
+	out := new(DataReplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataReplication) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataReplicationList) DeepCopyInto(out *DataReplicationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataReplication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataReplicationList.
+func (in *DataReplicationList) DeepCopy() *DataReplicationList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataReplicationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataReplicationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataReplicationSpec) DeepCopyInto(out *DataReplicationSpec) {
+	*out = *in
+	if in.RsyncOptions != nil {
+		in, out := &in.RsyncOptions, &out.RsyncOptions
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataReplicationSpec.
+func (in *DataReplicationSpec) DeepCopy() *DataReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataReplicationStatus) DeepCopyInto(out *DataReplicationStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulSyncTime != nil {
+		in, out := &in.LastSuccessfulSyncTime, &out.LastSuccessfulSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Lag != nil {
+		in, out := &in.Lag, &out.Lag
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Syncs != nil {
+		in, out := &in.Syncs, &out.Syncs
+		*out = make([]DataReplicationSync, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataReplicationStatus.
+func (in *DataReplicationStatus) DeepCopy() *DataReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataReplicationSync) DeepCopyInto(out *DataReplicationSync) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataReplicationSync.
+func (in *DataReplicationSync) DeepCopy() *DataReplicationSync {
+	if in == nil {
+		return nil
+	}
+	out := new(DataReplicationSync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSync) DeepCopyInto(out *DataSync) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSync.
+func (in *DataSync) DeepCopy() *DataSync {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataSync) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSyncList) DeepCopyInto(out *DataSyncList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataSync, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSyncList.
+func (in *DataSyncList) DeepCopy() *DataSyncList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSyncList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataSyncList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSyncSpec) DeepCopyInto(out *DataSyncSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSyncSpec.
+func (in *DataSyncSpec) DeepCopy() *DataSyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSyncStatus) DeepCopyInto(out *DataSyncStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSyncStatus.
+func (in *DataSyncStatus) DeepCopy() *DataSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitPopulator) DeepCopyInto(out *GitPopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitPopulator.
+func (in *GitPopulator) DeepCopy() *GitPopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(GitPopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitPopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitPopulatorList) DeepCopyInto(out *GitPopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GitPopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitPopulatorList.
+func (in *GitPopulatorList) DeepCopy() *GitPopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(GitPopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitPopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitPopulatorSpec) DeepCopyInto(out *GitPopulatorSpec) {
+	*out = *in
+	if in.Depth != nil {
+		in, out := &in.Depth, &out.Depth
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SparseCheckoutPaths != nil {
+		in, out := &in.SparseCheckoutPaths, &out.SparseCheckoutPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitPopulatorSpec.
+func (in *GitPopulatorSpec) DeepCopy() *GitPopulatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitPopulatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPPopulator) DeepCopyInto(out *HTTPPopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPPopulator.
+func (in *HTTPPopulator) DeepCopy() *HTTPPopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPPopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HTTPPopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPPopulatorList) DeepCopyInto(out *HTTPPopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HTTPPopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPPopulatorList.
+func (in *HTTPPopulatorList) DeepCopy() *HTTPPopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPPopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HTTPPopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPPopulatorSpec) DeepCopyInto(out *HTTPPopulatorSpec) {
+	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPPopulatorSpec.
+func (in *HTTPPopulatorSpec) DeepCopy() *HTTPPopulatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPPopulatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveMigration) DeepCopyInto(out *LiveMigration) {
+	*out = *in
+	if in.DeltaThreshold != nil {
+		in, out := &in.DeltaThreshold, &out.DeltaThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveMigration.
+func (in *LiveMigration) DeepCopy() *LiveMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveMigrationStatus) DeepCopyInto(out *LiveMigrationStatus) {
+	*out = *in
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]ScaledWorkload, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveMigrationStatus.
+func (in *LiveMigrationStatus) DeepCopy() *LiveMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSPopulator) DeepCopyInto(out *NFSPopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSPopulator.
+func (in *NFSPopulator) DeepCopy() *NFSPopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSPopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NFSPopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSPopulatorList) DeepCopyInto(out *NFSPopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NFSPopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSPopulatorList.
+func (in *NFSPopulatorList) DeepCopy() *NFSPopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSPopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NFSPopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSPopulatorSpec) DeepCopyInto(out *NFSPopulatorSpec) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSPopulatorSpec.
+func (in *NFSPopulatorSpec) DeepCopy() *NFSPopulatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSPopulatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIPopulator) DeepCopyInto(out *OCIPopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIPopulator.
+func (in *OCIPopulator) DeepCopy() *OCIPopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIPopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OCIPopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIPopulatorList) DeepCopyInto(out *OCIPopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OCIPopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIPopulatorList.
+func (in *OCIPopulatorList) DeepCopy() *OCIPopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIPopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OCIPopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIPopulatorSpec) DeepCopyInto(out *OCIPopulatorSpec) {
+	*out = *in
+	if in.Layers != nil {
+		in, out := &in.Layers, &out.Layers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIPopulatorSpec.
+func (in *OCIPopulatorSpec) DeepCopy() *OCIPopulatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIPopulatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCExport) DeepCopyInto(out *PVCExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCExport.
+func (in *PVCExport) DeepCopy() *PVCExport {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PVCExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCExportList) DeepCopyInto(out *PVCExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PVCExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCExportList.
+func (in *PVCExportList) DeepCopy() *PVCExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PVCExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCExportSpec) DeepCopyInto(out *PVCExportSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedSourceRanges != nil {
+		in, out := &in.AllowedSourceRanges, &out.AllowedSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCExportSpec.
+func (in *PVCExportSpec) DeepCopy() *PVCExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCExportStatus) DeepCopyInto(out *PVCExportStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCExportStatus.
+func (in *PVCExportStatus) DeepCopy() *PVCExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RclonePopulator) DeepCopyInto(out *RclonePopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RclonePopulator.
+func (in *RclonePopulator) DeepCopy() *RclonePopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(RclonePopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RclonePopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RclonePopulatorList) DeepCopyInto(out *RclonePopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RclonePopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RclonePopulatorList.
+func (in *RclonePopulatorList) DeepCopy() *RclonePopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(RclonePopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RclonePopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RclonePopulatorSpec) DeepCopyInto(out *RclonePopulatorSpec) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RclonePopulatorSpec.
+func (in *RclonePopulatorSpec) DeepCopy() *RclonePopulatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RclonePopulatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebindStatus) DeepCopyInto(out *RebindStatus) {
+	*out = *in
+	if in.SourcePVCLabels != nil {
+		in, out := &in.SourcePVCLabels, &out.SourcePVCLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RebindStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebindStatus.
+func (in *RebindStatus) DeepCopy() *RebindStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RebindStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebindStep) DeepCopyInto(out *RebindStep) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebindStep.
+func (in *RebindStep) DeepCopy() *RebindStep {
+	if in == nil {
+		return nil
+	}
+	out := new(RebindStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestorePopulator) DeepCopyInto(out *RestorePopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulator.
-func (in *DataPopulator) DeepCopy() *DataPopulator {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestorePopulator.
+func (in *RestorePopulator) DeepCopy() *RestorePopulator {
 	if in == nil {
 		return nil
 	}
-	out := new(DataPopulator)
+	out := new(RestorePopulator)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataPopulator) DeepCopyObject() runtime.Object {
+func (in *RestorePopulator) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -36,31 +1473,31 @@ func (in *DataPopulator) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataPopulatorList) DeepCopyInto(out *DataPopulatorList) {
+func (in *RestorePopulatorList) DeepCopyInto(out *RestorePopulatorList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DataPopulator, len(*in))
+		*out = make([]RestorePopulator, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorList.
-func (in *DataPopulatorList) DeepCopy() *DataPopulatorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestorePopulatorList.
+func (in *RestorePopulatorList) DeepCopy() *RestorePopulatorList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataPopulatorList)
+	out := new(RestorePopulatorList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataPopulatorList) DeepCopyObject() runtime.Object {
+func (in *RestorePopulatorList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -68,32 +1505,76 @@ func (in *DataPopulatorList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataPopulatorSpec) DeepCopyInto(out *DataPopulatorSpec) {
+func (in *RestorePopulatorSpec) DeepCopyInto(out *RestorePopulatorSpec) {
 	*out = *in
-	in.DestinationPVC.DeepCopyInto(&out.DestinationPVC)
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorSpec.
-func (in *DataPopulatorSpec) DeepCopy() *DataPopulatorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestorePopulatorSpec.
+func (in *RestorePopulatorSpec) DeepCopy() *RestorePopulatorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DataPopulatorSpec)
+	out := new(RestorePopulatorSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataPopulatorStatus) DeepCopyInto(out *DataPopulatorStatus) {
+func (in *RsyncExportTarget) DeepCopyInto(out *RsyncExportTarget) {
 	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPopulatorStatus.
-func (in *DataPopulatorStatus) DeepCopy() *DataPopulatorStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncExportTarget.
+func (in *RsyncExportTarget) DeepCopy() *RsyncExportTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(DataPopulatorStatus)
+	out := new(RsyncExportTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncOptions) DeepCopyInto(out *RsyncOptions) {
+	*out = *in
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HardLinks != nil {
+		in, out := &in.HardLinks, &out.HardLinks
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ACLs != nil {
+		in, out := &in.ACLs, &out.ACLs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Xattrs != nil {
+		in, out := &in.Xattrs, &out.Xattrs
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncOptions.
+func (in *RsyncOptions) DeepCopy() *RsyncOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncOptions)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -103,7 +1584,7 @@ func (in *RsyncPopulator) DeepCopyInto(out *RsyncPopulator) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncPopulator.
@@ -156,9 +1637,49 @@ func (in *RsyncPopulatorList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncPopulatorSource) DeepCopyInto(out *RsyncPopulatorSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncPopulatorSource.
+func (in *RsyncPopulatorSource) DeepCopy() *RsyncPopulatorSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncPopulatorSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RsyncPopulatorSpec) DeepCopyInto(out *RsyncPopulatorSpec) {
 	*out = *in
+	if in.BandwidthLimit != nil {
+		in, out := &in.BandwidthLimit, &out.BandwidthLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]RsyncPopulatorSource, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncPopulatorSpec.
@@ -170,3 +1691,245 @@ func (in *RsyncPopulatorSpec) DeepCopy() *RsyncPopulatorSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ExportTarget) DeepCopyInto(out *S3ExportTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ExportTarget.
+func (in *S3ExportTarget) DeepCopy() *S3ExportTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ExportTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHExportTarget) DeepCopyInto(out *SSHExportTarget) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHExportTarget.
+func (in *SSHExportTarget) DeepCopy() *SSHExportTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHExportTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaledWorkload) DeepCopyInto(out *ScaledWorkload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledWorkload.
+func (in *ScaledWorkload) DeepCopy() *ScaledWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaledWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceCluster) DeepCopyInto(out *SourceCluster) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceCluster.
+func (in *SourceCluster) DeepCopy() *SourceCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigration) DeepCopyInto(out *StatefulSetMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigration.
+func (in *StatefulSetMigration) DeepCopy() *StatefulSetMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StatefulSetMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigrationList) DeepCopyInto(out *StatefulSetMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StatefulSetMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationList.
+func (in *StatefulSetMigrationList) DeepCopy() *StatefulSetMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StatefulSetMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigrationSpec) DeepCopyInto(out *StatefulSetMigrationSpec) {
+	*out = *in
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationSpec.
+func (in *StatefulSetMigrationSpec) DeepCopy() *StatefulSetMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigrationStatus) DeepCopyInto(out *StatefulSetMigrationStatus) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]StatefulSetMigrationVolume, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationStatus.
+func (in *StatefulSetMigrationStatus) DeepCopy() *StatefulSetMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigrationVolume) DeepCopyInto(out *StatefulSetMigrationVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationVolume.
+func (in *StatefulSetMigrationVolume) DeepCopy() *StatefulSetMigrationVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigrationVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferStatus) DeepCopyInto(out *TransferStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferStatus.
+func (in *TransferStatus) DeepCopy() *TransferStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferWindow) DeepCopyInto(out *TransferWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferWindow.
+func (in *TransferWindow) DeepCopy() *TransferWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferWindowStatus) DeepCopyInto(out *TransferWindowStatus) {
+	*out = *in
+	in.NextTransition.DeepCopyInto(&out.NextTransition)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferWindowStatus.
+func (in *TransferWindowStatus) DeepCopy() *TransferWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferWindowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
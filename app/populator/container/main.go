@@ -0,0 +1,241 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	"github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/secret"
+)
+
+const (
+	prefix     = "openebs.io"
+	mountPath  = "/mnt"
+	devicePath = "/dev/block"
+
+	groupName  = "openebs.io"
+	apiVersion = "v1alpha1"
+	kind       = "ContainerPopulator"
+	resource   = "containerpopulators"
+)
+
+var (
+	gk  = schema.GroupKind{Group: groupName, Kind: kind}
+	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	kubeClient kubernetes.Interface
+
+	// allowedImagePrefixes restricts the images which can be used, every
+	// image is allowed if it is empty.
+	allowedImagePrefixes []string
+
+	envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// capabilities are the only capabilities of the container, which are
+	// needed to write files with any owner and mode into the volume.
+	capabilities = []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "SETGID", "SETUID"}
+)
+
+func main() {
+	klog.InitFlags(nil)
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		panic(err)
+	}
+
+	var (
+		imagePrefixes string
+	)
+	flag.StringVar(&imagePrefixes, "allowed-image-prefixes", "",
+		"Comma separated prefixes of the images allowed to be used for populating, eg: docker.io/myorg/. Every image is allowed if it is empty")
+	flag.Parse()
+
+	for _, p := range strings.Split(imagePrefixes, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			allowedImagePrefixes = append(allowedImagePrefixes, p)
+		}
+	}
+
+	namespace := os.Getenv("POD_NAMESPACE")
+
+	// The client is used to read the secrets of the populators
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+	kubeClient, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	populator.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
+}
+
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator.Pod, error) {
+	containerPopulator := internalv1alpha1.ContainerPopulator{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(u.UnstructuredContent(), &containerPopulator)
+	if err != nil {
+		return nil, err
+	}
+	spec := containerPopulator.Spec
+	name := containerPopulator.GetName()
+	namespace := containerPopulator.GetNamespace()
+
+	if spec.Image == "" {
+		return nil, fmt.Errorf("image is required in %s `%s`", kind, name)
+	}
+	if !imageAllowed(spec.Image) {
+		return nil, fmt.Errorf("image `%s` in %s `%s` is not allowed, allowed image prefixes are %s",
+			spec.Image, kind, name, strings.Join(allowedImagePrefixes, ", "))
+	}
+
+	// The secrets are in the namespace of the populator, while the pod
+	// runs in the namespace of the controller, so the values are copied
+	// into the secret of the pod, under the name of the variable.
+	secrets := map[string]map[string]string{}
+	getSecret := func(secretName string) (map[string]string, error) {
+		if data, ok := secrets[secretName]; ok {
+			return data, nil
+		}
+		data, err := secret.Get(kubeClient, namespace, secretName)
+		if err != nil {
+			return nil, err
+		}
+		secrets[secretName] = data
+		return data, nil
+	}
+	secretData := map[string][]byte{}
+	secretEnv := func(name, value string) corev1.EnvVar {
+		secretData[name] = []byte(value)
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: populator.SecretName},
+					Key:                  name,
+				},
+			},
+		}
+	}
+
+	var env []corev1.EnvVar
+	for _, from := range spec.EnvFrom {
+		data, err := getSecret(from.SecretRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !envNameRegex.MatchString(from.Prefix + k) {
+				return nil, fmt.Errorf("key `%s` of secret `%s` is not a valid environment variable name",
+					k, from.SecretRef.Name)
+			}
+			env = append(env, secretEnv(from.Prefix+k, data[k]))
+		}
+	}
+	for _, e := range spec.Env {
+		if !envNameRegex.MatchString(e.Name) {
+			return nil, fmt.Errorf("invalid environment variable name `%s` in %s `%s`", e.Name, kind, name)
+		}
+		if e.SecretKeyRef == nil {
+			env = append(env, corev1.EnvVar{Name: e.Name, Value: e.Value})
+			continue
+		}
+		data, err := getSecret(e.SecretKeyRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		v, ok := data[e.SecretKeyRef.Key]
+		if !ok {
+			return nil, fmt.Errorf("secret `%s` in `%s` namespace does not have the `%s` key",
+				e.SecretKeyRef.Name, namespace, e.SecretKeyRef.Key)
+		}
+		env = append(env, secretEnv(e.Name, v))
+	}
+
+	privileged := false
+	allowPrivilegeEscalation := false
+	automountToken := false
+	podSpec := &corev1.PodSpec{
+		// The populator pod must not get access to the cluster
+		AutomountServiceAccountToken: &automountToken,
+		SecurityContext: &corev1.PodSecurityContext{
+			SeccompProfile: &corev1.SeccompProfile{
+				Type: corev1.SeccompProfileTypeRuntimeDefault,
+			},
+		},
+		Containers: []corev1.Container{
+			{
+				Name:      populator.ContainerName,
+				Image:     spec.Image,
+				Command:   spec.Command,
+				Args:      spec.Args,
+				Env:       env,
+				Resources: spec.Resources,
+				SecurityContext: &corev1.SecurityContext{
+					Privileged:               &privileged,
+					AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+					Capabilities: &corev1.Capabilities{
+						Drop: []corev1.Capability{"ALL"},
+						Add:  capabilities,
+					},
+				},
+			},
+		},
+	}
+	if rawBlock {
+		// The block device is at devicePath instead of the mount path
+		podSpec.Containers[0].Env = append(podSpec.Containers[0].Env,
+			corev1.EnvVar{Name: "POPULATOR_DEVICE_PATH", Value: devicePath})
+	} else {
+		podSpec.Containers[0].Env = append(podSpec.Containers[0].Env,
+			corev1.EnvVar{Name: "POPULATOR_MOUNT_PATH", Value: mountPath})
+	}
+	return &populator.Pod{Spec: *podSpec, SecretData: secretData}, nil
+}
+
+func imageAllowed(image string) bool {
+	if len(allowedImagePrefixes) == 0 {
+		return true
+	}
+	for _, p := range allowedImagePrefixes {
+		if strings.HasPrefix(image, p) {
+			return true
+		}
+	}
+	return false
+}
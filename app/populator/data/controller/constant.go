@@ -26,6 +26,24 @@ const (
 	DpKind     = "DataPopulator"
 	DpResource = "datapopulators"
 
+	DeKind     = "DataExport"
+	DeResource = "dataexports"
+
+	PeKind     = "PVCExport"
+	PeResource = "pvcexports"
+
+	DsKind     = "DataSync"
+	DsResource = "datasyncs"
+
+	DrKind     = "DataReplication"
+	DrResource = "datareplications"
+
+	SmKind     = "StatefulSetMigration"
+	SmResource = "statefulsetmigrations"
+
+	DpsKind     = "DataPopulatorSet"
+	DpsResource = "datapopulatorsets"
+
 	createdByLabel = "openebs.io/created-by"
 	roleLabel      = "openebs.io/role"
 	managedByLabel = "openebs.io/managed-by"
@@ -38,8 +56,56 @@ const (
 	SourcePvcMountPath = "/data"
 
 	nodeNameAnnotation = "volume.kubernetes.io/selected-node"
+	// reclaimPolicyAnnotation keeps the reclaim policy of a pv which is
+	// retained while its pvc is rebound
+	reclaimPolicyAnnotation = "openebs.io/original-reclaim-policy"
+	// bandwidthLimitAnnotation is the bandwidth limit, in bytes per second,
+	// the rsync daemon pod is started with
+	bandwidthLimitAnnotation = "openebs.io/bandwidth-limit"
 
 	populatorFinalizer = "openebs.io/populate-target-protection"
+	// dataPopulatorFinalizer is set on the data populators having a source
+	// cluster till the rsync daemon of the source cluster is deleted
+	dataPopulatorFinalizer = "openebs.io/data-populator-protection"
+	// dataSyncFinalizer is set on the data syncs having a source pvc of
+	// another namespace till its rsync daemon is deleted
+	dataSyncFinalizer = "openebs.io/data-sync-protection"
+	kubeconfigKey     = "kubeconfig"
+
+	exportNamePrefix      = "data-export-"
+	exportRoleLabelValue  = "data-export"
+	exportSecretMountPath = "/etc/data-export"
+	exportRemoteName      = "DST"
+
+	pvcExportNamePrefix = "pvc-export-"
+	// keys of the connection secret of a pvc export
+	urlKey      = "url"
+	pathKey     = "path"
+	usernameKey = "username"
+	passwordKey = "password"
+	caKey       = "ca.crt"
+
+	dataSyncNamePrefix      = "data-sync-"
+	dataSyncRoleLabelValue  = "data-sync"
+	dataSyncDestinationPath = "/mnt"
+
+	dataReplicationNamePrefix     = "data-replication-"
+	dataReplicationRoleLabelValue = "data-replication"
+	// dataReplicationSyncHistory is the number of syncs kept in the status
+	dataReplicationSyncHistory = 10
+
+	liveRoleLabelValue = "live-migration"
+
+	statefulSetMigrationNamePrefix = "statefulset-migration-"
+	// statefulSetKey is the key of the configmap keeping the statefulset
+	// while it is recreated
+	statefulSetKey = "statefulset.json"
+
+	dataPopulatorSetNamePrefix = "data-populator-set-"
+
+	rsyncPort         = 873
+	rsyncTLSPort      = 874
+	rsyncTLSMountPath = "/etc/rsync-tls"
 
 	RsyncNamePrefix = "rsync-daemon-"
 	rsyncUsername   = "openebs-user"
@@ -27,7 +27,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -52,14 +54,45 @@ var (
 
 	dpGK  = schema.GroupKind{Group: GroupOpenebsIO, Kind: DpKind}
 	dpGVR = schema.GroupVersionResource{Group: GroupOpenebsIO, Version: VersionV1alpha1, Resource: DpResource}
+
+	deGVR = schema.GroupVersionResource{Group: GroupOpenebsIO, Version: VersionV1alpha1, Resource: DeResource}
+
+	peGVR = schema.GroupVersionResource{Group: GroupOpenebsIO, Version: VersionV1alpha1, Resource: PeResource}
+
+	dsGVR = schema.GroupVersionResource{Group: GroupOpenebsIO, Version: VersionV1alpha1, Resource: DsResource}
+
+	drGVR = schema.GroupVersionResource{Group: GroupOpenebsIO, Version: VersionV1alpha1, Resource: DrResource}
+
+	smGVR = schema.GroupVersionResource{Group: GroupOpenebsIO, Version: VersionV1alpha1, Resource: SmResource}
+
+	dpsGVR = schema.GroupVersionResource{Group: GroupOpenebsIO, Version: VersionV1alpha1, Resource: DpsResource}
 )
 
 type controller struct {
-	kubeClient    *kubernetes.Clientset
-	dynamicClient dynamic.Interface
-	dpLister      dynamiclister.Lister
-	dpSynced      cache.InformerSynced
-	workqueue     workqueue.RateLimitingInterface
+	kubeClient       *kubernetes.Clientset
+	dynamicClient    dynamic.Interface
+	dpLister         dynamiclister.Lister
+	dpSynced         cache.InformerSynced
+	workqueue        workqueue.RateLimitingInterface
+	deLister         dynamiclister.Lister
+	deSynced         cache.InformerSynced
+	exportQueue      workqueue.RateLimitingInterface
+	peLister         dynamiclister.Lister
+	peSynced         cache.InformerSynced
+	pvcExportQueue   workqueue.RateLimitingInterface
+	dsLister         dynamiclister.Lister
+	dsSynced         cache.InformerSynced
+	dataSyncQueue    workqueue.RateLimitingInterface
+	drLister         dynamiclister.Lister
+	drSynced         cache.InformerSynced
+	replicationQueue workqueue.RateLimitingInterface
+	smLister         dynamiclister.Lister
+	smSynced         cache.InformerSynced
+	migrationQueue   workqueue.RateLimitingInterface
+	dpsLister        dynamiclister.Lister
+	dpsSynced        cache.InformerSynced
+	setQueue         workqueue.RateLimitingInterface
+	transfers        *transferTracker
 }
 
 func RunController(cfg *rest.Config) {
@@ -74,6 +107,20 @@ func RunController(cfg *rest.Config) {
 		os.Exit(1) // second signal. Exit directly.
 	}()
 
+	if MaxAggregateBandwidth != "" {
+		bandwidth, err := resource.ParseQuantity(MaxAggregateBandwidth)
+		if err != nil {
+			klog.Fatalf("Invalid aggregate bandwidth limit: %v", err)
+		}
+		maxAggregateBandwidth = bandwidth.Value()
+	}
+
+	if DefaultTransferWindow.Start != "" {
+		if _, err := getTransferWindowStatus(&DefaultTransferWindow, time.Now()); err != nil {
+			klog.Fatalf("Invalid transfer window: %v", err)
+		}
+	}
+
 	kubeClient, err := kubernetes.NewForConfig(cfg)
 	if nil != err {
 		klog.Fatalf("Failed to create kube client: %v", err)
@@ -86,12 +133,37 @@ func RunController(cfg *rest.Config) {
 
 	dynamicInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 30*time.Second)
 	dpInformer := dynamicInformerFactory.ForResource(dpGVR).Informer()
+	deInformer := dynamicInformerFactory.ForResource(deGVR).Informer()
+	peInformer := dynamicInformerFactory.ForResource(peGVR).Informer()
+	dsInformer := dynamicInformerFactory.ForResource(dsGVR).Informer()
+	drInformer := dynamicInformerFactory.ForResource(drGVR).Informer()
+	smInformer := dynamicInformerFactory.ForResource(smGVR).Informer()
+	dpsInformer := dynamicInformerFactory.ForResource(dpsGVR).Informer()
 	c := &controller{
-		kubeClient:    kubeClient,
-		dynamicClient: dynamicClient,
-		dpLister:      dynamiclister.New(dpInformer.GetIndexer(), dpGVR),
-		dpSynced:      dpInformer.HasSynced,
-		workqueue:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		kubeClient:       kubeClient,
+		dynamicClient:    dynamicClient,
+		dpLister:         dynamiclister.New(dpInformer.GetIndexer(), dpGVR),
+		dpSynced:         dpInformer.HasSynced,
+		workqueue:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		deLister:         dynamiclister.New(deInformer.GetIndexer(), deGVR),
+		deSynced:         deInformer.HasSynced,
+		exportQueue:      workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		peLister:         dynamiclister.New(peInformer.GetIndexer(), peGVR),
+		peSynced:         peInformer.HasSynced,
+		pvcExportQueue:   workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		dsLister:         dynamiclister.New(dsInformer.GetIndexer(), dsGVR),
+		dsSynced:         dsInformer.HasSynced,
+		dataSyncQueue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		drLister:         dynamiclister.New(drInformer.GetIndexer(), drGVR),
+		drSynced:         drInformer.HasSynced,
+		replicationQueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		smLister:         dynamiclister.New(smInformer.GetIndexer(), smGVR),
+		smSynced:         smInformer.HasSynced,
+		migrationQueue:   workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		dpsLister:        dynamiclister.New(dpsInformer.GetIndexer(), dpsGVR),
+		dpsSynced:        dpsInformer.HasSynced,
+		setQueue:         workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		transfers:        &transferTracker{started: map[string]internalv1alpha1.TransferStatus{}},
 	}
 
 	dpInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -102,6 +174,60 @@ func RunController(cfg *rest.Config) {
 		DeleteFunc: c.handleDataPopulator,
 	})
 
+	// The export jobs are checked on every resync of the data exports
+	deInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleDataExport,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.handleDataExport(newObj)
+		},
+		DeleteFunc: c.handleDataExport,
+	})
+
+	// The address of the service is checked on every resync of the pvc exports
+	peInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handlePVCExport,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.handlePVCExport(newObj)
+		},
+		DeleteFunc: c.handlePVCExport,
+	})
+
+	// The rsync daemon and the sync job are checked on every resync of the data syncs
+	dsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleDataSync,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.handleDataSync(newObj)
+		},
+		DeleteFunc: c.handleDataSync,
+	})
+
+	// The data replications are also queued when their next sync is due
+	drInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleDataReplication,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.handleDataReplication(newObj)
+		},
+		DeleteFunc: c.handleDataReplication,
+	})
+
+	// The data populators of the volumes are checked on every resync of the statefulset migrations
+	smInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleStatefulSetMigration,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.handleStatefulSetMigration(newObj)
+		},
+		DeleteFunc: c.handleStatefulSetMigration,
+	})
+
+	// The selected pvcs and the data populators are checked on every resync of the data populator sets
+	dpsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleDataPopulatorSet,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.handleDataPopulatorSet(newObj)
+		},
+		DeleteFunc: c.handleDataPopulatorSet,
+	})
+
 	dynamicInformerFactory.Start(stopCh)
 	if err := c.run(stopCh); nil != err {
 		klog.Fatalf("Failed to run controller: %v", err)
@@ -119,12 +245,25 @@ func (c *controller) handleDataPopulator(obj interface{}) {
 func (c *controller) run(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
-
-	if ok := cache.WaitForCacheSync(stopCh, c.dpSynced); !ok {
+	defer c.exportQueue.ShutDown()
+	defer c.pvcExportQueue.ShutDown()
+	defer c.dataSyncQueue.ShutDown()
+	defer c.replicationQueue.ShutDown()
+	defer c.migrationQueue.ShutDown()
+	defer c.setQueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(stopCh, c.dpSynced, c.deSynced, c.peSynced, c.dsSynced, c.drSynced,
+		c.smSynced, c.dpsSynced); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
 	go wait.Until(c.runWorker, time.Second, stopCh)
+	go wait.Until(c.runExportWorker, time.Second, stopCh)
+	go wait.Until(c.runPVCExportWorker, time.Second, stopCh)
+	go wait.Until(c.runDataSyncWorker, time.Second, stopCh)
+	go wait.Until(c.runDataReplicationWorker, time.Second, stopCh)
+	go wait.Until(c.runStatefulSetMigrationWorker, time.Second, stopCh)
+	go wait.Until(c.runDataPopulatorSetWorker, time.Second, stopCh)
 	<-stopCh
 	return nil
 }
@@ -168,6 +307,8 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 	if err != nil {
 		if errors.IsNotFound(err) {
 			utilruntime.HandleError(fmt.Errorf("data populator '%s' in work queue no longer exists", key))
+			// The transfer of the deleted data populator is not counted anymore
+			c.requeuePendingTransfers()
 			return nil
 		}
 		return fmt.Errorf("error getting data populator error: %s", err)
@@ -180,9 +321,35 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 			unstruct.GetName(), unstruct.GetNamespace(), err)
 	}
 
+	// The rsync daemon of a source cluster is not garbage collected, so it
+	// is deleted before the data populator is gone
+	if dataPopulator.Spec.SourceCluster != nil {
+		if dataPopulator.GetDeletionTimestamp() != nil {
+			if dataPopulator.Status.State != internalv1alpha1.StatusCompleted {
+				dptc, err := templateFromDataPopulator(dataPopulator)
+				if err != nil {
+					return fmt.Errorf("error creating template config error: %s", err)
+				}
+				if err := c.deleteRemoteRsyncDaemon(ctx, &dataPopulator, dptc); err != nil {
+					return err
+				}
+			}
+			return c.ensureDataPopulatorFinalizer(&dataPopulator, false)
+		}
+		if dataPopulator.Status.State != internalv1alpha1.StatusCompleted &&
+			!containsString(dataPopulator.GetFinalizers(), dataPopulatorFinalizer) {
+			return c.ensureDataPopulatorFinalizer(&dataPopulator, true)
+		}
+	}
+
 	// If the status is completed or failed then don't perform any action
 	if dataPopulator.Status.State == internalv1alpha1.StatusCompleted ||
-		dataPopulator.Status.State == internalv1alpha1.StatusFailed {
+		dataPopulator.Status.State == internalv1alpha1.StatusFailed ||
+		dataPopulator.Status.State == internalv1alpha1.StatusReadyForCutover {
+		// The source pvc is rebound once the data has been populated
+		if dataPopulator.Spec.RebindSourcePVC && dataPopulator.Status.State != internalv1alpha1.StatusFailed {
+			return c.syncRebind(ctx, &dataPopulator)
+		}
 		return nil
 	}
 
@@ -196,6 +363,19 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 		return nil
 	}
 
+	// The sub paths are not cleaned into the root of the volumes, and the
+	// sources are not set with the features which don't support them
+	if err := validateDataPopulator(&dataPopulator); err != nil {
+		clone := dataPopulator.DeepCopy()
+		clone.Status.State = internalv1alpha1.StatusFailed
+		clone.Status.Message = err.Error()
+		if err := c.updateDataPopulator(clone); err != nil {
+			return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
+				dataPopulator.GetName(), dataPopulator.GetNamespace(), err)
+		}
+		return nil
+	}
+
 	// Create a template config of data populator
 	dataPopulatorClone := dataPopulator.DeepCopy()
 	dptc, err := templateFromDataPopulator(*dataPopulatorClone)
@@ -203,12 +383,29 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 		return fmt.Errorf("error creating template config error: %s", err)
 	}
 
-	// Check whether the source pvc is already created so that rsync daemon can work properly
-	_, err = c.kubeClient.CoreV1().PersistentVolumeClaims(dataPopulator.Spec.SourcePVCNamespace).
-		Get(context.TODO(), dataPopulator.Spec.SourcePVC, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s",
-			dataPopulator.Spec.SourcePVC, namespace, err)
+	// The data is copied by the pass jobs instead of the rsync populator
+	if dataPopulator.Spec.Live != nil {
+		return c.syncLiveMigration(ctx, &dataPopulator, dptc)
+	}
+
+	// The rsync daemon is created in the cluster of the source pvc
+	source := c
+	if dataPopulator.Spec.SourceCluster != nil {
+		source, err = c.getSourceClusterController(ctx, &dataPopulator)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check whether the source pvcs are already created so that rsync daemon can work properly
+	var sourcePVC *corev1.PersistentVolumeClaim
+	for _, stc := range dptc.getSourceConfigs() {
+		sourcePVC, err = source.kubeClient.CoreV1().PersistentVolumeClaims(stc.sourcePVCNamespace).
+			Get(context.TODO(), stc.sourcePVCName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s",
+				stc.sourcePVCName, stc.sourcePVCNamespace, err)
+		}
 	}
 
 	// Create destination PVC to where the data is to be populated
@@ -218,12 +415,10 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 			destinationPvcTemplate.GetName(), namespace, err)
 	}
 
-	// Create rsync-populator resource which will take care of populating the destination pvc
+	// The rsync-populator resource which populates the destination pvc is
+	// created only once the transfer is admitted, so that no populator pod
+	// is run while the data populator is suspended or queued
 	rsyncPopulatorTemplate := dptc.getRsyncPopulatorTemplate()
-	if err := c.ensurePopulator(true, namespace, &rsyncPopulatorTemplate); err != nil {
-		return fmt.Errorf("error ensuring(true) populator `%s` in `%s` namespace, error: %s",
-			rsyncPopulatorTemplate.GetName(), namespace, err)
-	}
 
 	destinationPVC, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).
 		Get(context.TODO(), destinationPvcTemplate.Name, metav1.GetOptions{})
@@ -264,11 +459,19 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 		return nil
 	}
 
+	// The destination pvc is bound once the data is populated, till then
+	// the data populator can be suspended
+	if dataPopulator.Spec.Suspend && destinationPVC.Spec.VolumeName == "" {
+		return c.suspendDataPopulator(ctx, source, &dataPopulator, dptc, destinationPVC)
+	}
+
 	// Check for the finalizer which is added by the rsync-populator which is there till
 	// the data population is not completed. This will help us to know whether population of
-	// data is still needed or not.
+	// data is still needed or not. The destination pvc is bound only once the data is
+	// populated, so the population is needed too while the rsync-populator resource
+	// is not created yet.
 	// Ref: https://github.com/kubernetes-csi/lib-volume-populator/blob/e9508a3a026888d47da5fce7d7ae2856c7810e21/populator-machinery/controller.go#L492
-	want := false
+	want := destinationPVC.Spec.VolumeName == ""
 	if finalizers := destinationPVC.GetFinalizers(); finalizers != nil {
 		for _, f := range finalizers {
 			if f == populatorFinalizer {
@@ -279,37 +482,217 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 	}
 
 	if want {
-		if dataPopulator.Status.State != internalv1alpha1.StatusInProgress {
-			// change the status of data-populator
+		// The transfer is started only in the transfer window, and if it is running
+		// it is paused outside the window when the window pauses it
+		started := dataPopulator.Status.Transfer != nil && dataPopulator.Status.Transfer.StartTime != nil
+		var windowStatus *internalv1alpha1.TransferWindowStatus
+		if window := getTransferWindow(&dataPopulator); window != nil {
+			now := time.Now()
+			windowStatus, err = getTransferWindowStatus(window, now)
+			if err != nil {
+				clone := dataPopulator.DeepCopy()
+				clone.Status.State = internalv1alpha1.StatusFailed
+				clone.Status.Message = err.Error()
+				if err := c.updateDataPopulator(clone); err != nil {
+					return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
+						dataPopulator.GetName(), dataPopulator.GetNamespace(), err)
+				}
+				return nil
+			}
+			// We'll check the window again when it opens or closes
+			c.workqueue.AddAfter(key, windowStatus.NextTransition.Sub(now))
+
+			if !windowStatus.Open && (!started || window.PauseOutsideWindow) {
+				clone := dataPopulator.DeepCopy()
+				clone.Status.QueuePosition = 0
+				clone.Status.Window = windowStatus
+				nextTransition := windowStatus.NextTransition.Format(time.RFC3339)
+				if started {
+					// The transfer is stopped like a suspended one, and started
+					// again from the copied data when the window opens
+					if err := c.stopTransfer(ctx, source, &dataPopulator, dptc, destinationPVC); err != nil {
+						return err
+					}
+					clone.Status.State = internalv1alpha1.StatusPaused
+					// The pods recreated on resuming are not interruptions
+					clone.Status.Transfer.DaemonPodUID = ""
+					clone.Status.Transfer.PopulatorPodUID = ""
+					clone.Status.Message = "paused outside the transfer window, resumes at " + nextTransition
+				} else {
+					if err := c.ensurePopulator(false, namespace, &rsyncPopulatorTemplate); err != nil {
+						return fmt.Errorf("error ensuring(false) populator `%s` in `%s` namespace, error: %s",
+							rsyncPopulatorTemplate.GetName(), namespace, err)
+					}
+					clone.Status.State = internalv1alpha1.StatusPending
+					clone.Status.Message = "waiting for the transfer window, opens at " + nextTransition
+					clone.Status.Transfer = nil
+				}
+				if equality.Semantic.DeepEqual(clone.Status, dataPopulator.Status) {
+					return nil
+				}
+				if err := c.updateDataPopulator(clone); err != nil {
+					return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
+						dataPopulator.GetName(), dataPopulator.GetNamespace(), err)
+				}
+				return nil
+			}
+		}
+
+		// The transfer waits while any of the concurrency limits is reached, the
+		// node of the source volume is known only if it is in this cluster
+		transfer := internalv1alpha1.TransferStatus{
+			DestinationNode: selectedNode,
+			StorageClass:    sc.Name,
+			BandwidthLimit:  dptc.bandwidthLimit,
+		}
+		if dataPopulator.Spec.SourceCluster == nil && len(dptc.sources) == 0 {
+			transfer.SourceNode = sourcePVC.GetAnnotations()[nodeNameAnnotation]
+		}
+		transfer.Priority, err = c.getTransferPriority(ctx, &dataPopulator)
+		if err != nil {
+			return err
+		}
+		admitted, position, reason, err := c.admitTransfer(key, &dataPopulator, &transfer)
+		if err != nil {
+			return err
+		}
+		if !admitted {
+			if err := c.ensurePopulator(false, namespace, &rsyncPopulatorTemplate); err != nil {
+				return fmt.Errorf("error ensuring(false) populator `%s` in `%s` namespace, error: %s",
+					rsyncPopulatorTemplate.GetName(), namespace, err)
+			}
 			clone := dataPopulator.DeepCopy()
-			clone.Status.State = internalv1alpha1.StatusInProgress
+			clone.Status.State = internalv1alpha1.StatusPending
+			clone.Status.Message = "waiting for a transfer to finish, " + reason
+			clone.Status.QueuePosition = position
+			clone.Status.Transfer = &transfer
+			clone.Status.Window = windowStatus
+			if equality.Semantic.DeepEqual(clone.Status, dataPopulator.Status) {
+				return nil
+			}
 			if err := c.updateDataPopulator(clone); err != nil {
 				return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
 					dataPopulator.GetName(), dataPopulator.GetNamespace(), err)
 			}
+			return nil
+		}
+		if started {
+			bandwidthLimit := transfer.BandwidthLimit
+			transfer = *dataPopulator.Status.Transfer
+			transfer.BandwidthLimit = bandwidthLimit
+		} else {
+			now := metav1.Now()
+			transfer.StartTime = &now
+		}
+		// The rsync daemon sends the data at the share of the transfer of the
+		// aggregate bandwidth, it is restarted when the share changes
+		dptc.bandwidthLimit = transfer.BandwidthLimit
+		restarted, err := c.restartTransferForBandwidth(ctx, source, dptc, destinationPVC)
+		if err != nil {
+			return err
+		}
+		if restarted {
+			// The pods are created again on the next resync, they are not
+			// counted as interruptions
+			transfer.DaemonPodUID = ""
+			transfer.PopulatorPodUID = ""
 		}
 
 		// Create all the resources needed for the rsync daemon to be up and running
-		if err := c.ensureRsyncDaemon(true, dptc, dptc.sourcePVCNamespace); err != nil {
+		message := ""
+		if dataPopulator.Spec.SourceCluster != nil {
+			ready, err := c.ensureRemoteRsyncDaemon(ctx, source, &dataPopulator, dptc)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				// We'll check the address again on the next resync
+				message = "waiting for the address of service `" + dptc.name + "` in the source cluster"
+			}
+		} else if err := c.ensureRsyncDaemon(true, dptc, dptc.sourcePVCNamespace); err != nil {
 			return err
 		}
+		// The populator pod is created after the rsync daemon, it is retried
+		// by the rsync populator till the daemon is ready
+		if message == "" {
+			if err := c.ensurePopulator(true, namespace, &rsyncPopulatorTemplate); err != nil {
+				return fmt.Errorf("error ensuring(true) populator `%s` in `%s` namespace, error: %s",
+					rsyncPopulatorTemplate.GetName(), namespace, err)
+			}
+		}
+
+		// The pods which are recreated while the data is copied are counted as
+		// interruptions, rsync resumes from the data which is already copied
+		interrupted, err := c.observeTransferPods(ctx, source, dptc, destinationPVC, &transfer)
+		if err != nil {
+			return err
+		}
+		sources, err := c.getSourcesStatus(ctx, &dataPopulator, dptc, destinationPVC)
+		if err != nil {
+			return err
+		}
+		if message == "" && len(sources) > 0 {
+			message = getSourcesMessage(sources)
+		}
+
+		// change the status of data-populator
+		clone := dataPopulator.DeepCopy()
+		clone.Status.State = internalv1alpha1.StatusInProgress
+		clone.Status.Message = message
+		clone.Status.QueuePosition = 0
+		clone.Status.Transfer = &transfer
+		clone.Status.Window = windowStatus
+		clone.Status.Sources = sources
+		if interrupted {
+			now := metav1.Now()
+			clone.Status.Interruptions++
+			clone.Status.LastInterruptionTime = &now
+		}
+		if !equality.Semantic.DeepEqual(clone.Status, dataPopulator.Status) {
+			if err := c.updateDataPopulator(clone); err != nil {
+				return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
+					dataPopulator.GetName(), dataPopulator.GetNamespace(), err)
+			}
+		}
 		return nil
 	}
 
 	// Delete all the rsync daemon resources when the finalizer set by the rsync-populator is gone.
 	// The finalizer is removed only when the source data has been fully populated into into the desired destination.
-	if err := c.ensureRsyncDaemon(false, dptc, dptc.sourcePVCNamespace); err != nil {
+	if dataPopulator.Spec.SourceCluster != nil {
+		if err := c.deleteRemoteRsyncDaemon(ctx, &dataPopulator, dptc); err != nil {
+			return err
+		}
+	} else if err := c.ensureRsyncDaemon(false, dptc, dptc.sourcePVCNamespace); err != nil {
 		return err
 	}
 
-	// Update the data-populator status to mark as completed
+	// Update the data-populator status to mark as completed, the finalizer
+	// is not needed anymore as the source cluster is cleaned up
 	if dataPopulator.Status.State != internalv1alpha1.StatusCompleted {
 		clone := dataPopulator.DeepCopy()
 		clone.Status.State = internalv1alpha1.StatusCompleted
+		clone.Status.Message = ""
+		// All of the sources are copied once the destination pvc is populated
+		now := metav1.Now()
+		clone.Status.Sources, err = c.getSourcesStatus(ctx, &dataPopulator, dptc, destinationPVC)
+		if err != nil {
+			return err
+		}
+		for i := range clone.Status.Sources {
+			if clone.Status.Sources[i].State != internalv1alpha1.StatusCompleted {
+				clone.Status.Sources[i].State = internalv1alpha1.StatusCompleted
+				clone.Status.Sources[i].CompletionTime = &now
+			}
+		}
+		if dataPopulator.Spec.SourceCluster != nil {
+			clone.SetFinalizers(removeString(clone.GetFinalizers(), dataPopulatorFinalizer))
+		}
 		if err := c.updateDataPopulator(clone); err != nil {
 			return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
 				dataPopulator.GetName(), dataPopulator.GetNamespace(), err)
 		}
+		c.requeuePendingTransfers()
 	}
 
 	return nil
@@ -317,6 +700,18 @@ func (c *controller) syncPopulator(ctx context.Context, key, namespace, name str
 
 // ensureRsyncDaemon ensures the desired state of all the rsync daemon resources
 func (c *controller) ensureRsyncDaemon(want bool, dptc *templateConfig, namespace string) error {
+	// Every source which is merged into the destination pvc has its own
+	// rsync daemon, in the namespace of its pvc
+	if len(dptc.sources) > 0 {
+		for _, stc := range dptc.sources {
+			stc.bandwidthLimit = dptc.bandwidthLimit
+			if err := c.ensureRsyncDaemon(want, stc, stc.sourcePVCNamespace); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	cmTemplate := dptc.getCmTemplate()
 	if err := c.ensureConfigMap(want, namespace, &cmTemplate); err != nil {
 		return fmt.Errorf("error ensuring(true) configmap `%s` in `%s` namespace, error: %s",
@@ -572,3 +967,44 @@ func (c *controller) ensureService(want bool, namespace string, svc *corev1.Serv
 	}
 	return nil
 }
+
+/*
+if found and not created by the data-populator then return error
+if want and found return nil
+if !want and !found return nil
+if want and !found -> create return error/nil
+if !want and found -> delete return error/nil
+*/
+func (c *controller) ensureSecret(want bool, namespace string, secret *corev1.Secret) error {
+	secretClone := secret.DeepCopy()
+	found := true
+	obj, err := c.kubeClient.CoreV1().Secrets(namespace).
+		Get(context.TODO(), secretClone.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			found = false
+		} else {
+			return err
+		}
+	}
+	if found && (obj.GetLabels() == nil || obj.GetLabels()[createdByLabel] != componentName) {
+		return fmt.Errorf("secret `%s` found but not created by this operator", obj.GetName())
+	}
+	if want && found {
+		return nil
+	}
+	if !want && !found {
+		return nil
+	}
+	if want && !found {
+		_, err := c.kubeClient.CoreV1().Secrets(namespace).
+			Create(context.TODO(), secretClone, metav1.CreateOptions{})
+		return err
+	}
+	if !want && found {
+		err := c.kubeClient.CoreV1().Secrets(namespace).
+			Delete(context.TODO(), secretClone.Name, metav1.DeleteOptions{})
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func (c *controller) handleDataPopulatorSet(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.setQueue.Add(key)
+}
+
+func (c *controller) runDataPopulatorSetWorker() {
+	c.runQueueWorker(c.setQueue, c.syncDataPopulatorSet)
+}
+
+// syncDataPopulatorSet creates the data populators of the selected pvcs, at
+// most maxConcurrent of them running at a time, and counts their results
+func (c *controller) syncDataPopulatorSet(ctx context.Context, key, namespace, name string) error {
+	unstruct, err := c.dpsLister.Namespace(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The data populators are garbage collected with the set
+			utilruntime.HandleError(fmt.Errorf("data populator set '%s' in work queue no longer exists", key))
+			return nil
+		}
+		return fmt.Errorf("error getting data populator set error: %s", err)
+	}
+
+	set := internalv1alpha1.DataPopulatorSet{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+		&set); err != nil {
+		return fmt.Errorf("error converting data populator set `%s` in `%s` namespace error: %s",
+			unstruct.GetName(), unstruct.GetNamespace(), err)
+	}
+
+	// If the status is completed or failed then don't perform any action
+	if set.Status.State == internalv1alpha1.StatusCompleted ||
+		set.Status.State == internalv1alpha1.StatusFailed {
+		return nil
+	}
+	status := set.Status.DeepCopy()
+
+	sourceNamespace := set.Spec.SourcePVCNamespace
+	if sourceNamespace == "" {
+		sourceNamespace = set.Namespace
+	}
+	if set.Spec.Template.RebindSourcePVC && sourceNamespace != set.Namespace {
+		return c.updateDataPopulatorSetStatus(&set, internalv1alpha1.StatusFailed,
+			"rebindSourcePVC needs the set to be in the namespace of the pvcs", status)
+	}
+	selector := metav1.ListOptions{}
+	if set.Spec.Selector != nil {
+		labelSelector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+		if err != nil {
+			return c.updateDataPopulatorSetStatus(&set, internalv1alpha1.StatusFailed,
+				"invalid selector: "+err.Error(), status)
+		}
+		selector.LabelSelector = labelSelector.String()
+	}
+	pvcList, err := c.kubeClient.CoreV1().PersistentVolumeClaims(sourceNamespace).List(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("error listing pvcs in `%s` namespace error: %s", sourceNamespace, err)
+	}
+
+	// The selected pvcs are kept in the status, so that a pvc which is
+	// deleted and recreated by rebinding is not populated again
+	pvcs := map[string]*corev1.PersistentVolumeClaim{}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		pvcs[pvc.Name] = pvc
+		// The destination pvcs are not populated again
+		if pvc.GetLabels()[createdByLabel] == componentName || pvc.Status.Phase != corev1.ClaimBound ||
+			findSetMember(status.DataPopulators, pvc.Name) != nil {
+			continue
+		}
+		// The pvcs already in the destination storage class are not moved
+		if getSetStorageClassName(set, pvc) == getStorageClassName(pvc) {
+			continue
+		}
+		status.DataPopulators = append(status.DataPopulators, internalv1alpha1.DataPopulatorSetMember{
+			PVC:           pvc.Name,
+			DataPopulator: set.Name + "-" + pvc.Name,
+		})
+	}
+	sort.Slice(status.DataPopulators, func(i, j int) bool {
+		return status.DataPopulators[i].PVC < status.DataPopulators[j].PVC
+	})
+
+	succeeded, failed, running := int32(0), int32(0), int32(0)
+	pending := []*internalv1alpha1.DataPopulatorSetMember{}
+	for i := range status.DataPopulators {
+		member := &status.DataPopulators[i]
+		unstruct, err := c.dpLister.Namespace(set.Namespace).Get(member.DataPopulator)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("error getting data populator `%s` in `%s` namespace error: %s",
+					member.DataPopulator, set.Namespace, err)
+			}
+			if member.State == internalv1alpha1.StatusFailed {
+				failed++
+				continue
+			}
+			pending = append(pending, member)
+			continue
+		}
+		dp := internalv1alpha1.DataPopulator{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+			&dp); err != nil {
+			return fmt.Errorf("error converting data populator `%s` in `%s` namespace error: %s",
+				unstruct.GetName(), unstruct.GetNamespace(), err)
+		}
+		member.State = getSetMemberState(&dp)
+		switch member.State {
+		case internalv1alpha1.StatusCompleted:
+			succeeded++
+		case internalv1alpha1.StatusFailed:
+			failed++
+		default:
+			running++
+		}
+	}
+
+	// Create the data populators of the pending pvcs, till maxConcurrent of them are running
+	for len(pending) > 0 && (set.Spec.MaxConcurrent <= 0 || running < set.Spec.MaxConcurrent) {
+		member := pending[0]
+		pending = pending[1:]
+		pvc, ok := pvcs[member.PVC]
+		if !ok {
+			// The pvc is deleted before it could be populated
+			member.State = internalv1alpha1.StatusFailed
+			failed++
+			continue
+		}
+		dp := getSetDataPopulatorTemplate(set, member.DataPopulator, pvc)
+		dpMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dp)
+		if err != nil {
+			return err
+		}
+		_, err = c.dynamicClient.Resource(dpGVR).Namespace(set.Namespace).
+			Create(ctx, &unstructured.Unstructured{Object: dpMap}, metav1.CreateOptions{})
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("error creating data populator `%s` in `%s` namespace error: %s",
+				dp.Name, set.Namespace, err)
+		}
+		running++
+	}
+
+	status.Succeeded = succeeded
+	status.Failed = failed
+	status.Running = running
+	status.Pending = int32(len(pending))
+	state := internalv1alpha1.StatusInProgress
+	if running == 0 && len(pending) == 0 {
+		state = internalv1alpha1.StatusCompleted
+		if failed > 0 {
+			state = internalv1alpha1.StatusFailed
+		}
+	}
+	message := fmt.Sprintf("%d succeeded, %d failed, %d running, %d pending",
+		status.Succeeded, status.Failed, status.Running, status.Pending)
+	return c.updateDataPopulatorSetStatus(&set, state, message, status)
+}
+
+// getSetMemberState returns Completed or Failed once the data populator is
+// finished, including rebinding the source pvc if it is set
+func getSetMemberState(dp *internalv1alpha1.DataPopulator) string {
+	if dp.Status.State != internalv1alpha1.StatusCompleted || !dp.Spec.RebindSourcePVC {
+		return dp.Status.State
+	}
+	if dp.Status.Rebind == nil {
+		return internalv1alpha1.StatusInProgress
+	}
+	if dp.Status.Rebind.State == internalv1alpha1.StatusCompleted ||
+		dp.Status.Rebind.State == internalv1alpha1.StatusFailed {
+		return dp.Status.Rebind.State
+	}
+	return internalv1alpha1.StatusInProgress
+}
+
+func findSetMember(members []internalv1alpha1.DataPopulatorSetMember, pvc string) *internalv1alpha1.DataPopulatorSetMember {
+	for i := range members {
+		if members[i].PVC == pvc {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+// updateDataPopulatorSetStatus updates the status of the data populator set, if it is changed
+func (c *controller) updateDataPopulatorSetStatus(set *internalv1alpha1.DataPopulatorSet, state, message string,
+	status *internalv1alpha1.DataPopulatorSetStatus) error {
+	clone := set.DeepCopy()
+	clone.Status = *status.DeepCopy()
+	clone.Status.State = state
+	clone.Status.Message = message
+	if equality.Semantic.DeepEqual(clone.Status, set.Status) {
+		return nil
+	}
+
+	setMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(clone)
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicClient.Resource(dpsGVR).Namespace(clone.GetNamespace()).
+		Update(context.TODO(), &unstructured.Unstructured{Object: setMap}, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("error updating status of data populator set `%s` in `%s` namespace, error: %s",
+			set.GetName(), set.GetNamespace(), err)
+	}
+	return nil
+}
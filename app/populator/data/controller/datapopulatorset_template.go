@@ -0,0 +1,112 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// getSetDataPopulatorTemplate returns the data populator of a pvc selected by
+// the set, the destination pvc spec of the template is filled in from the pvc
+func getSetDataPopulatorTemplate(set internalv1alpha1.DataPopulatorSet, name string,
+	pvc *corev1.PersistentVolumeClaim) internalv1alpha1.DataPopulator {
+	isController := true
+	spec := *set.Spec.Template.DestinationPVC.DeepCopy()
+	spec.DataSource = nil
+	spec.DataSourceRef = nil
+	if len(spec.AccessModes) == 0 {
+		spec.AccessModes = pvc.Spec.AccessModes
+	}
+	if _, ok := spec.Resources.Requests[corev1.ResourceStorage]; !ok {
+		if spec.Resources.Requests == nil {
+			spec.Resources.Requests = corev1.ResourceList{}
+		}
+		spec.Resources.Requests[corev1.ResourceStorage] = pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	}
+	if spec.VolumeMode == nil {
+		spec.VolumeMode = pvc.Spec.VolumeMode
+	}
+	storageClassName := getSetStorageClassName(set, pvc)
+	spec.StorageClassName = &storageClassName
+
+	return internalv1alpha1.DataPopulator{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       DpKind,
+			APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: set.Namespace,
+			Labels: map[string]string{
+				createdByLabel: componentName,
+				managedByLabel: componentName,
+				appLabel:       dataPopulatorSetNamePrefix + set.Name,
+			},
+			// The data populators are garbage collected with the set
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+					Kind:       DpsKind,
+					Name:       set.Name,
+					UID:        set.UID,
+					Controller: &isController,
+				},
+			},
+		},
+		Spec: internalv1alpha1.DataPopulatorSpec{
+			SourcePVC:          pvc.Name,
+			SourcePVCNamespace: pvc.Namespace,
+			DestinationPVC:     spec,
+			RebindSourcePVC:    set.Spec.Template.RebindSourcePVC,
+			Priority:           set.Spec.Template.Priority,
+			PriorityClassName:  set.Spec.Template.PriorityClassName,
+			BandwidthLimit:     set.Spec.Template.BandwidthLimit,
+			RsyncOptions:       set.Spec.Template.RsyncOptions,
+			SourceSubPath:      set.Spec.Template.SourceSubPath,
+			DestinationSubPath: set.Spec.Template.DestinationSubPath,
+			Include:            set.Spec.Template.Include,
+			Exclude:            set.Spec.Template.Exclude,
+		},
+	}
+}
+
+// getSetStorageClassName returns the storage class of the destination pvc of
+// a pvc selected by the set, with the substitutions of the template replaced
+func getSetStorageClassName(set internalv1alpha1.DataPopulatorSet, pvc *corev1.PersistentVolumeClaim) string {
+	sourceStorageClass := getStorageClassName(pvc)
+	if set.Spec.Template.DestinationPVC.StorageClassName == nil {
+		return sourceStorageClass
+	}
+	return strings.NewReplacer(
+		"$(PVC_NAME)", pvc.Name,
+		"$(PVC_NAMESPACE)", pvc.Namespace,
+		"$(STORAGE_CLASS)", sourceStorageClass,
+	).Replace(*set.Spec.Template.DestinationPVC.StorageClassName)
+}
+
+// getStorageClassName returns the storage class of the pvc
+func getStorageClassName(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func TestGetSetDataPopulatorTemplate(t *testing.T) {
+	stringPtr := func(s string) *string { return &s }
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-0", Namespace: "apps"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: stringPtr("standard"),
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+	tests := map[string]struct {
+		storageClassName *string
+		pvcStorageClass  *string
+		wantStorageClass string
+	}{
+		"defaults to the storage class of the pvc": {
+			pvcStorageClass:  stringPtr("standard"),
+			wantStorageClass: "standard",
+		},
+		"fixed storage class": {
+			storageClassName: stringPtr("fast"),
+			pvcStorageClass:  stringPtr("standard"),
+			wantStorageClass: "fast",
+		},
+		"storage class of the pvc": {
+			storageClassName: stringPtr("$(STORAGE_CLASS)-replicated"),
+			pvcStorageClass:  stringPtr("standard"),
+			wantStorageClass: "standard-replicated",
+		},
+		"name and namespace of the pvc": {
+			storageClassName: stringPtr("$(PVC_NAMESPACE)-$(PVC_NAME)"),
+			pvcStorageClass:  stringPtr("standard"),
+			wantStorageClass: "apps-data-0",
+		},
+		"pvc without a storage class": {
+			storageClassName: stringPtr("fast-$(STORAGE_CLASS)"),
+			wantStorageClass: "fast-",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			set := internalv1alpha1.DataPopulatorSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "move", Namespace: "apps", UID: "uid"},
+				Spec: internalv1alpha1.DataPopulatorSetSpec{
+					Template: internalv1alpha1.DataPopulatorTemplate{
+						DestinationPVC: corev1.PersistentVolumeClaimSpec{
+							StorageClassName: test.storageClassName,
+						},
+					},
+				},
+			}
+			source := pvc.DeepCopy()
+			source.Spec.StorageClassName = test.pvcStorageClass
+
+			dp := getSetDataPopulatorTemplate(set, "move-data-0", source)
+			if got := getSetStorageClassName(set, source); got != test.wantStorageClass {
+				t.Errorf("getSetStorageClassName() = %q, want %q", got, test.wantStorageClass)
+			}
+			spec := dp.Spec.DestinationPVC
+			if spec.StorageClassName == nil || *spec.StorageClassName != test.wantStorageClass {
+				t.Errorf("storage class = %v, want %q", spec.StorageClassName, test.wantStorageClass)
+			}
+			if !reflect.DeepEqual(spec.AccessModes, pvc.Spec.AccessModes) {
+				t.Errorf("access modes = %v, want %v", spec.AccessModes, pvc.Spec.AccessModes)
+			}
+			if got, want := spec.Resources.Requests[corev1.ResourceStorage], resource.MustParse("1Gi"); got.Cmp(want) != 0 {
+				t.Errorf("storage request = %s, want %s", got.String(), want.String())
+			}
+			if dp.Spec.SourcePVC != "data-0" || dp.Spec.SourcePVCNamespace != "apps" {
+				t.Errorf("source pvc = %s/%s, want apps/data-0", dp.Spec.SourcePVCNamespace, dp.Spec.SourcePVC)
+			}
+			if len(dp.OwnerReferences) != 1 || dp.OwnerReferences[0].UID != set.UID {
+				t.Errorf("owner references = %v, want the set", dp.OwnerReferences)
+			}
+			// The template of the set is not changed by the substitutions
+			if !reflect.DeepEqual(set.Spec.Template.DestinationPVC.StorageClassName, test.storageClassName) {
+				t.Errorf("template storage class changed to %v", set.Spec.Template.DestinationPVC.StorageClassName)
+			}
+		})
+	}
+}
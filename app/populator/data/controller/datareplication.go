@@ -0,0 +1,431 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	"github.com/openebs/data-populator/pkg/rsync"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	// finishedJobTTL is how long the finished sync jobs are kept for their logs
+	finishedJobTTL = time.Hour
+)
+
+func (c *controller) handleDataReplication(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.replicationQueue.Add(key)
+}
+
+func (c *controller) runDataReplicationWorker() {
+	c.runQueueWorker(c.replicationQueue, c.syncDataReplication)
+}
+
+func (c *controller) syncDataReplication(ctx context.Context, key, namespace, name string) error {
+	unstruct, err := c.drLister.Namespace(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The rsync daemon and the sync jobs are garbage collected with the data replication
+			utilruntime.HandleError(fmt.Errorf("data replication '%s' in work queue no longer exists", key))
+			return nil
+		}
+		return fmt.Errorf("error getting data replication error: %s", err)
+	}
+
+	dataReplication := internalv1alpha1.DataReplication{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+		&dataReplication); err != nil {
+		return fmt.Errorf("error converting data replication `%s` in `%s` namespace error: %s",
+			unstruct.GetName(), unstruct.GetNamespace(), err)
+	}
+	status := dataReplication.Status.DeepCopy()
+
+	// An invalid spec is checked again when the data replication is updated
+	schedule, err := cron.ParseStandard(dataReplication.Spec.Schedule)
+	if err != nil {
+		return c.updateDataReplicationStatus(&dataReplication, internalv1alpha1.StatusFailed,
+			fmt.Sprintf("invalid schedule `%s`: %s", dataReplication.Spec.Schedule, err), status)
+	}
+	if dataReplication.Spec.SourcePVC == dataReplication.Spec.DestinationPVC {
+		return c.updateDataReplicationStatus(&dataReplication, internalv1alpha1.StatusFailed,
+			"source and destination pvc must be different", status)
+	}
+	for _, pvcName := range []string{dataReplication.Spec.SourcePVC, dataReplication.Spec.DestinationPVC} {
+		pvc, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).
+			Get(ctx, pvcName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s",
+				pvcName, namespace, err)
+		}
+		if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+			return c.updateDataReplicationStatus(&dataReplication, internalv1alpha1.StatusFailed,
+				"block volumes are not supported by "+DrKind, status)
+		}
+	}
+
+	tc := templateFromDataReplication(dataReplication)
+
+	// Record the result of the running sync once its job is done
+	if status.Active != "" {
+		done, err := c.checkReplicationJob(ctx, namespace, status)
+		if err != nil || !done {
+			return err
+		}
+	}
+
+	if dataReplication.Spec.Suspend {
+		// The source pvc is not kept mounted while the replication is suspended
+		if status.Active == "" {
+			if err := c.ensureRsyncDaemon(false, tc, namespace); err != nil {
+				return err
+			}
+		}
+		return c.updateDataReplicationStatus(&dataReplication, internalv1alpha1.StatusSuspended,
+			"replication is suspended", status)
+	}
+
+	// The daemon runs on the node where the source pvc is in use, as the
+	// application keeps running while the data is replicated.
+	tc.nodeName, err = c.getPVCConsumerNode(ctx, namespace, tc.sourcePVCName, tc.name)
+	if err != nil {
+		return err
+	}
+	if status.Active == "" {
+		if err := c.ensureDaemonNode(ctx, tc); err != nil {
+			return err
+		}
+	}
+	if _, err := c.ensureCredentialsSecret(ctx, tc, ""); err != nil {
+		return fmt.Errorf("error ensuring credentials secret `%s` in `%s` namespace, error: %s",
+			tc.credentialsSecret, namespace, err)
+	}
+	if err := c.ensureRsyncDaemon(true, tc, namespace); err != nil {
+		return err
+	}
+
+	state, message := getReplicationState(status)
+	if status.Active != "" {
+		return c.updateDataReplicationStatus(&dataReplication, state, message, status)
+	}
+
+	// Start a sync if one is due, the missed syncs are not run
+	now := time.Now()
+	last := dataReplication.CreationTimestamp.Time
+	if status.LastScheduleTime != nil {
+		last = status.LastScheduleTime.Time
+	}
+	next := schedule.Next(last)
+	if now.Before(next) {
+		c.replicationQueue.AddAfter(key, next.Sub(now))
+		return c.updateDataReplicationStatus(&dataReplication, state, message, status)
+	}
+
+	pod, err := c.kubeClient.CoreV1().Pods(namespace).Get(ctx, tc.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting pod `%s` in `%s` namespace error: %s", tc.name, namespace, err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		// We'll check the pod again on the next resync
+		return c.updateDataReplicationStatus(&dataReplication, state,
+			"waiting for rsync daemon `"+tc.name+"` to be running", status)
+	}
+
+	jobTemplate := getDataReplicationJobTemplate(dataReplication, tc, now)
+	if err := c.ensureJob(true, namespace, &jobTemplate); err != nil {
+		return fmt.Errorf("error ensuring(true) job `%s` in `%s` namespace, error: %s",
+			jobTemplate.GetName(), namespace, err)
+	}
+	start := metav1.NewTime(now)
+	status.Active = jobTemplate.Name
+	status.LastScheduleTime = &start
+	state, message = getReplicationState(status)
+	return c.updateDataReplicationStatus(&dataReplication, state, message, status)
+}
+
+// checkReplicationJob records the result of the active sync in the status,
+// it returns false if the job is still running. The finished jobs are
+// deleted by the ttl controller.
+func (c *controller) checkReplicationJob(ctx context.Context, namespace string,
+	status *internalv1alpha1.DataReplicationStatus) (bool, error) {
+	sync := internalv1alpha1.DataReplicationSync{
+		Job:   status.Active,
+		State: internalv1alpha1.StatusFailed,
+	}
+	if status.LastScheduleTime != nil {
+		sync.StartTime = *status.LastScheduleTime
+	}
+
+	done, failed, bytes, err := c.getRsyncJobResult(ctx, namespace, status.Active)
+	if err != nil || !done {
+		return false, err
+	}
+	if !failed {
+		sync.State = internalv1alpha1.StatusCompleted
+		sync.BytesTransferred = bytes
+	}
+
+	now := metav1.Now()
+	sync.CompletionTime = &now
+	if sync.State == internalv1alpha1.StatusCompleted {
+		if status.LastSuccessfulSyncTime != nil {
+			status.Lag = &metav1.Duration{Duration: now.Sub(status.LastSuccessfulSyncTime.Time)}
+		} else {
+			status.Lag = &metav1.Duration{Duration: now.Sub(sync.StartTime.Time)}
+		}
+		start := sync.StartTime
+		status.LastSuccessfulSyncTime = &start
+	}
+	status.Syncs = append([]internalv1alpha1.DataReplicationSync{sync}, status.Syncs...)
+	if len(status.Syncs) > dataReplicationSyncHistory {
+		status.Syncs = status.Syncs[:dataReplicationSyncHistory]
+	}
+	status.Active = ""
+	return true, nil
+}
+
+// getRsyncJobResult returns whether the job is done, whether it failed and
+// the bytes transferred by it. A job which is not found is taken as failed.
+func (c *controller) getRsyncJobResult(ctx context.Context, namespace, name string) (bool, bool, int64, error) {
+	job, err := c.kubeClient.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return true, true, 0, nil
+		}
+		return false, false, 0, fmt.Errorf("error getting job `%s` in `%s` namespace error: %s",
+			name, namespace, err)
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true, true, 0, nil
+		}
+	}
+	if job.Status.Succeeded == 0 {
+		// We'll check the job again on the next resync
+		return false, false, 0, nil
+	}
+	bytes, err := c.getRsyncJobBytes(ctx, job)
+	return true, false, bytes, err
+}
+
+// getRsyncJobBytes returns the bytes transferred by the succeeded pod of
+// the job, which writes them to its termination message
+func (c *controller) getRsyncJobBytes(ctx context.Context, job *batchv1.Job) (int64, error) {
+	pods, err := c.kubeClient.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "job-name=" + job.Name,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error listing pods of job `%s` in `%s` namespace error: %s",
+			job.Name, job.Namespace, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Terminated == nil {
+				continue
+			}
+			bytes, err := strconv.ParseInt(strings.TrimSpace(cs.State.Terminated.Message), 10, 64)
+			if err == nil {
+				return bytes, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// getPVCConsumers returns the running pods, other than the rsync daemon,
+// which mount the pvc
+func (c *controller) getPVCConsumers(ctx context.Context, namespace, pvcName,
+	daemonName string) ([]corev1.Pod, error) {
+	pods, err := c.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods in `%s` namespace error: %s", namespace, err)
+	}
+	consumers := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.Name == daemonName || pod.Spec.NodeName == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
+				consumers = append(consumers, pod)
+				break
+			}
+		}
+	}
+	return consumers, nil
+}
+
+// getPVCConsumerNode returns the node of a running pod, other than the
+// rsync daemon, which mounts the pvc
+func (c *controller) getPVCConsumerNode(ctx context.Context, namespace, pvcName, daemonName string) (string, error) {
+	consumers, err := c.getPVCConsumers(ctx, namespace, pvcName, daemonName)
+	if err != nil || len(consumers) == 0 {
+		return "", err
+	}
+	return consumers[0].Spec.NodeName, nil
+}
+
+// ensureDaemonNode deletes the rsync daemon pod if it is not running on the
+// node where the source pvc is in use, it is created again on that node
+func (c *controller) ensureDaemonNode(ctx context.Context, tc *templateConfig) error {
+	if tc.nodeName == "" {
+		return nil
+	}
+	pod, err := c.kubeClient.CoreV1().Pods(tc.sourcePVCNamespace).Get(ctx, tc.name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error getting pod `%s` in `%s` namespace error: %s",
+			tc.name, tc.sourcePVCNamespace, err)
+	}
+	if pod.Spec.NodeName == "" || pod.Spec.NodeName == tc.nodeName {
+		return nil
+	}
+	return c.ensurePod(false, tc.sourcePVCNamespace, pod)
+}
+
+// getReplicationState returns the state and message of the replication
+// from the result of its syncs
+func getReplicationState(status *internalv1alpha1.DataReplicationStatus) (string, string) {
+	if status.Active != "" {
+		return internalv1alpha1.StatusInProgress, "running sync job `" + status.Active + "`"
+	}
+	if len(status.Syncs) > 0 && status.Syncs[0].State == internalv1alpha1.StatusFailed {
+		return internalv1alpha1.StatusFailed, "sync job `" + status.Syncs[0].Job + "` failed"
+	}
+	return internalv1alpha1.StatusReady, "waiting for the next sync"
+}
+
+// updateDataReplicationStatus updates the status of the data replication, if it is changed
+func (c *controller) updateDataReplicationStatus(dr *internalv1alpha1.DataReplication, state, message string,
+	status *internalv1alpha1.DataReplicationStatus) error {
+	clone := dr.DeepCopy()
+	clone.Status = *status.DeepCopy()
+	clone.Status.State = state
+	clone.Status.Message = message
+	if equality.Semantic.DeepEqual(clone.Status, dr.Status) {
+		return nil
+	}
+
+	drMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(clone)
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicClient.Resource(drGVR).Namespace(clone.GetNamespace()).
+		Update(context.TODO(), &unstructured.Unstructured{Object: drMap}, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("error updating status of data replication `%s` in `%s` namespace, error: %s",
+			dr.GetName(), dr.GetNamespace(), err)
+	}
+	return nil
+}
+
+// templateFromDataReplication returns the template config of the rsync
+// daemon serving the source pvc of the data replication
+func templateFromDataReplication(dr internalv1alpha1.DataReplication) *templateConfig {
+	isController := true
+	return &templateConfig{
+		name:               dataReplicationNamePrefix + dr.Name,
+		sourcePVCName:      dr.Spec.SourcePVC,
+		sourcePVCNamespace: dr.Namespace,
+		imageName:          RsyncServerImage,
+		rsyncUsername:      rsyncUsername,
+		credentialsSecret:  dataReplicationNamePrefix + dr.Name,
+		readOnly:           true,
+		rsyncOptions:       dr.Spec.RsyncOptions,
+		serviceType:        corev1.ServiceTypeClusterIP,
+		// The resources are garbage collected with the data replication
+		ownerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+				Kind:       DrKind,
+				Name:       dr.Name,
+				UID:        dr.UID,
+				Controller: &isController,
+			},
+		},
+	}
+}
+
+// getDataReplicationJobTemplate returns the job of a sync, which writes the
+// size of the transferred files to its termination message
+func getDataReplicationJobTemplate(dr internalv1alpha1.DataReplication, tc *templateConfig,
+	now time.Time) batchv1.Job {
+	name := tc.name + "-" + strconv.FormatInt(now.Unix()/60, 10)
+	job := tc.getRsyncClientJobTemplate(name, dr.Spec.DestinationPVC, dataReplicationRoleLabelValue,
+		tc.getIncrementalSyncArgs(getDeleteRsyncArgs(tc.rsyncOptions)...))
+	ttl := int32(finishedJobTTL.Seconds())
+	job.Spec.TTLSecondsAfterFinished = &ttl
+	return job
+}
+
+// getDeleteRsyncArgs returns the rsync args of the options, deleting the files
+// of the destination which are deleted from the source since the last sync
+func getDeleteRsyncArgs(options *internalv1alpha1.RsyncOptions) []string {
+	deleteOptions := &internalv1alpha1.RsyncOptions{}
+	if options != nil {
+		deleteOptions = options.DeepCopy()
+	}
+	deleteOptions.Delete = true
+	return rsync.Args(deleteOptions)
+}
+
+// getIncrementalSyncArgs returns the args of the rsync client copying the
+// changes into the destination with the given options, the size of the
+// transferred files is written to the termination message.
+func (tc *templateConfig) getIncrementalSyncArgs(options ...string) []string {
+	script := &shell.Script{}
+	destination := dataSyncDestinationPath
+	if tc.destinationSubPath != "" {
+		destination += "/" + tc.destinationSubPath
+		script.Run("mkdir", "-p", destination)
+	}
+	quoted := ""
+	for _, option := range options {
+		quoted += shell.Quote(option) + " "
+	}
+	script.Raw("rsync -v --stats " + quoted + shell.Quote(tc.getRsyncSource()) + " " +
+		shell.Quote(destination+"/") + " | tee /tmp/rsync.log").
+		Raw("sed -n 's/^Total transferred file size: \\([0-9,]*\\) bytes.*/\\1/p' /tmp/rsync.log " +
+			"| tr -d , > /dev/termination-log")
+	return script.Args()
+}
@@ -0,0 +1,277 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func (c *controller) handleDataSync(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.dataSyncQueue.Add(key)
+}
+
+func (c *controller) runDataSyncWorker() {
+	c.runQueueWorker(c.dataSyncQueue, c.syncDataSync)
+}
+
+func (c *controller) syncDataSync(ctx context.Context, key, namespace, name string) error {
+	unstruct, err := c.dsLister.Namespace(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The rsync daemon and the sync job are garbage collected with the data sync
+			utilruntime.HandleError(fmt.Errorf("data sync '%s' in work queue no longer exists", key))
+			return nil
+		}
+		return fmt.Errorf("error getting data sync error: %s", err)
+	}
+
+	dataSync := internalv1alpha1.DataSync{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+		&dataSync); err != nil {
+		return fmt.Errorf("error converting data sync `%s` in `%s` namespace error: %s",
+			unstruct.GetName(), unstruct.GetNamespace(), err)
+	}
+
+	// The rsync daemon of a source pvc of another namespace is not garbage
+	// collected, so it is deleted before the data sync is gone
+	if getDataSyncSourceNamespace(dataSync) != namespace {
+		if dataSync.GetDeletionTimestamp() != nil {
+			if err := c.deleteDataSyncDaemon(templateFromDataSync(dataSync), namespace); err != nil {
+				return err
+			}
+			return c.ensureDataSyncFinalizer(&dataSync, false)
+		}
+		if dataSync.Status.State != internalv1alpha1.StatusCompleted &&
+			dataSync.Status.State != internalv1alpha1.StatusFailed &&
+			!containsString(dataSync.GetFinalizers(), dataSyncFinalizer) {
+			return c.ensureDataSyncFinalizer(&dataSync, true)
+		}
+	}
+
+	// If the status is completed or failed then don't perform any action
+	if dataSync.Status.State == internalv1alpha1.StatusCompleted ||
+		dataSync.Status.State == internalv1alpha1.StatusFailed {
+		return nil
+	}
+
+	tc := templateFromDataSync(dataSync)
+	jobTemplate := getDataSyncJobTemplate(dataSync, tc)
+
+	// The destination is overwritten, so it must be confirmed explicitly
+	if dataSync.Spec.SourcePVC == dataSync.Spec.DestinationPVC && tc.sourcePVCNamespace == namespace {
+		return c.failDataSync(&dataSync, "source and destination pvc must be different")
+	}
+	if dataSync.Spec.ConfirmOverwrite != dataSync.Spec.DestinationPVC {
+		return c.failDataSync(&dataSync, fmt.Sprintf("confirmOverwrite must be set to `%s` "+
+			"to overwrite the data of the destination pvc", dataSync.Spec.DestinationPVC))
+	}
+
+	// Check whether the source and destination pvcs are already created so that they can be mounted
+	for _, ref := range []struct{ name, namespace string }{
+		{dataSync.Spec.SourcePVC, tc.sourcePVCNamespace},
+		{dataSync.Spec.DestinationPVC, namespace},
+	} {
+		pvc, err := c.kubeClient.CoreV1().PersistentVolumeClaims(ref.namespace).
+			Get(ctx, ref.name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s",
+				ref.name, ref.namespace, err)
+		}
+		if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+			return c.failDataSync(&dataSync, "block volumes are not supported by "+DsKind)
+		}
+	}
+
+	credentials, err := c.ensureCredentialsSecret(ctx, tc, "")
+	if err != nil {
+		return fmt.Errorf("error ensuring credentials secret `%s` in `%s` namespace, error: %s",
+			tc.credentialsSecret, tc.sourcePVCNamespace, err)
+	}
+	if namespace != tc.sourcePVCNamespace {
+		// The sync job runs in the namespace of the destination pvc
+		if err := c.ensureConnectionSecret(ctx, namespace, tc.credentialsSecret, jobTemplate.OwnerReferences,
+			map[string][]byte{passwordKey: credentials[passwordKey]}); err != nil {
+			return fmt.Errorf("error ensuring secret `%s` in `%s` namespace, error: %s",
+				tc.credentialsSecret, namespace, err)
+		}
+	}
+
+	// Create all the resources needed for the rsync daemon to be up and running
+	if err := c.ensureRsyncDaemon(true, tc, tc.sourcePVCNamespace); err != nil {
+		return err
+	}
+
+	if dataSync.Status.State == "" {
+		clone := dataSync.DeepCopy()
+		now := metav1.Now()
+		clone.Status.State = internalv1alpha1.StatusInProgress
+		clone.Status.Message = fmt.Sprintf("syncing pvc `%s` of `%s` namespace into pvc `%s`",
+			dataSync.Spec.SourcePVC, tc.sourcePVCNamespace, dataSync.Spec.DestinationPVC)
+		clone.Status.StartTime = &now
+		if err := c.updateDataSync(clone); err != nil {
+			return fmt.Errorf("error updating status of data sync `%s` in `%s` namespace, error: %s",
+				dataSync.GetName(), dataSync.GetNamespace(), err)
+		}
+		return nil
+	}
+
+	// The sync job is created once the rsync daemon is running, so that it
+	// doesn't use up its retries while the daemon is starting
+	pod, err := c.kubeClient.CoreV1().Pods(tc.sourcePVCNamespace).Get(ctx, tc.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting pod `%s` in `%s` namespace error: %s",
+			tc.name, tc.sourcePVCNamespace, err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		// We'll check the pod again on the next resync
+		return nil
+	}
+	if err := c.ensureJob(true, namespace, &jobTemplate); err != nil {
+		return fmt.Errorf("error ensuring(true) job `%s` in `%s` namespace, error: %s",
+			jobTemplate.GetName(), namespace, err)
+	}
+
+	job, err := c.kubeClient.BatchV1().Jobs(namespace).Get(ctx, jobTemplate.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting job `%s` in `%s` namespace error: %s",
+			jobTemplate.Name, namespace, err)
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			// The failed job is kept so that the logs of its pods can be checked,
+			// the rsync daemon is deleted so that the source pvc is not mounted.
+			if err := c.deleteDataSyncDaemon(tc, namespace); err != nil {
+				return err
+			}
+			return c.failDataSync(&dataSync, fmt.Sprintf("sync job `%s` failed: %s",
+				job.Name, cond.Message))
+		}
+	}
+	if job.Status.Succeeded == 0 {
+		// We'll check the job again on the next resync
+		return nil
+	}
+
+	// Delete the sync job and the rsync daemon once the data has been synced
+	if err := c.ensureJob(false, namespace, &jobTemplate); err != nil {
+		return fmt.Errorf("error ensuring(false) job `%s` in `%s` namespace, error: %s",
+			jobTemplate.GetName(), namespace, err)
+	}
+	if err := c.deleteDataSyncDaemon(tc, namespace); err != nil {
+		return err
+	}
+	if namespace != tc.sourcePVCNamespace {
+		secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tc.credentialsSecret}}
+		if err := c.ensureSecret(false, namespace, &secret); err != nil {
+			return fmt.Errorf("error ensuring(false) secret `%s` in `%s` namespace, error: %s",
+				secret.Name, namespace, err)
+		}
+	}
+
+	clone := dataSync.DeepCopy()
+	now := metav1.Now()
+	clone.Status.State = internalv1alpha1.StatusCompleted
+	clone.Status.Message = fmt.Sprintf("synced pvc `%s` of `%s` namespace into pvc `%s`",
+		dataSync.Spec.SourcePVC, tc.sourcePVCNamespace, dataSync.Spec.DestinationPVC)
+	clone.Status.CompletionTime = &now
+	clone.SetFinalizers(removeString(clone.GetFinalizers(), dataSyncFinalizer))
+	if err := c.updateDataSync(clone); err != nil {
+		return fmt.Errorf("error updating status of data sync `%s` in `%s` namespace, error: %s",
+			dataSync.GetName(), dataSync.GetNamespace(), err)
+	}
+	return nil
+}
+
+// deleteDataSyncDaemon deletes the rsync daemon and its credentials secret,
+// which are not garbage collected if the source pvc is in another namespace
+func (c *controller) deleteDataSyncDaemon(tc *templateConfig, namespace string) error {
+	if err := c.ensureRsyncDaemon(false, tc, tc.sourcePVCNamespace); err != nil {
+		return err
+	}
+	credentials := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tc.credentialsSecret}}
+	if err := c.ensureSecret(false, tc.sourcePVCNamespace, &credentials); err != nil {
+		return fmt.Errorf("error ensuring(false) secret `%s` in `%s` namespace, error: %s",
+			credentials.Name, tc.sourcePVCNamespace, err)
+	}
+	return nil
+}
+
+// failDataSync marks the data sync as failed with the given message
+func (c *controller) failDataSync(ds *internalv1alpha1.DataSync, message string) error {
+	clone := ds.DeepCopy()
+	clone.Status.State = internalv1alpha1.StatusFailed
+	clone.Status.Message = message
+	clone.SetFinalizers(removeString(clone.GetFinalizers(), dataSyncFinalizer))
+	if err := c.updateDataSync(clone); err != nil {
+		return fmt.Errorf("error updating status of data sync `%s` in `%s` namespace, error: %s",
+			ds.GetName(), ds.GetNamespace(), err)
+	}
+	return nil
+}
+
+// ensureDataSyncFinalizer adds or removes the finalizer of the data sync
+func (c *controller) ensureDataSyncFinalizer(ds *internalv1alpha1.DataSync, want bool) error {
+	if want == containsString(ds.GetFinalizers(), dataSyncFinalizer) {
+		return nil
+	}
+	finalizers := removeString(ds.GetFinalizers(), dataSyncFinalizer)
+	if want {
+		finalizers = append(finalizers, dataSyncFinalizer)
+	}
+
+	clone := ds.DeepCopy()
+	clone.SetFinalizers(finalizers)
+	if err := c.updateDataSync(clone); err != nil {
+		return fmt.Errorf("error updating finalizers of data sync `%s` in `%s` namespace, error: %s",
+			ds.GetName(), ds.GetNamespace(), err)
+	}
+	return nil
+}
+
+// updateDataSync updates a data sync object
+func (c *controller) updateDataSync(ds *internalv1alpha1.DataSync) error {
+	dsMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ds.DeepCopy())
+	if err != nil {
+		return err
+	}
+
+	dsUnstruct := &unstructured.Unstructured{
+		Object: dsMap,
+	}
+
+	_, err = c.dynamicClient.Resource(dsGVR).Namespace(ds.GetNamespace()).
+		Update(context.TODO(), dsUnstruct, metav1.UpdateOptions{})
+	return err
+}
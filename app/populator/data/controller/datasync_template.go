@@ -0,0 +1,171 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	"github.com/openebs/data-populator/pkg/rsync"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	// dataSyncBackoffLimit is the number of retries of the sync job
+	dataSyncBackoffLimit = 3
+)
+
+// templateFromDataSync returns the template config of the rsync daemon
+// serving the source pvc of the data sync
+func templateFromDataSync(ds internalv1alpha1.DataSync) *templateConfig {
+	tc := &templateConfig{
+		name:               dataSyncNamePrefix + ds.Name,
+		sourcePVCName:      ds.Spec.SourcePVC,
+		sourcePVCNamespace: getDataSyncSourceNamespace(ds),
+		imageName:          RsyncServerImage,
+		rsyncUsername:      rsyncUsername,
+		credentialsSecret:  dataSyncNamePrefix + ds.Name,
+		readOnly:           true,
+		serviceType:        corev1.ServiceTypeClusterIP,
+	}
+	// The resources are garbage collected with the data sync, those in the
+	// namespace of a source pvc of another namespace are deleted by the
+	// controller as an owner can't be in another namespace
+	if tc.sourcePVCNamespace == ds.Namespace {
+		tc.ownerReferences = getDataSyncOwnerReferences(ds)
+	}
+	return tc
+}
+
+func getDataSyncSourceNamespace(ds internalv1alpha1.DataSync) string {
+	if ds.Spec.SourcePVCNamespace != "" {
+		return ds.Spec.SourcePVCNamespace
+	}
+	return ds.Namespace
+}
+
+func getDataSyncOwnerReferences(ds internalv1alpha1.DataSync) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{
+		{
+			APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+			Kind:       DsKind,
+			Name:       ds.Name,
+			UID:        ds.UID,
+			Controller: &isController,
+		},
+	}
+}
+
+// getDataSyncJobTemplate returns the job copying the data from the rsync
+// daemon into the destination pvc, deleting the extra files of the destination.
+// The job runs in the namespace of the data sync, where the destination is.
+func getDataSyncJobTemplate(ds internalv1alpha1.DataSync, tc *templateConfig) batchv1.Job {
+	args := append([]string{"rsync", "--verbose"}, rsync.Args(&internalv1alpha1.RsyncOptions{Delete: true})...)
+	script := &shell.Script{}
+	script.Run(append(args, tc.getRsyncSource(), dataSyncDestinationPath+"/")...)
+	job := tc.getRsyncClientJobTemplate(tc.name, ds.Spec.DestinationPVC, dataSyncRoleLabelValue, script.Args())
+	job.Namespace = ds.Namespace
+	job.OwnerReferences = getDataSyncOwnerReferences(ds)
+	return job
+}
+
+// getRsyncSource returns the url of the data of the rsync daemon, the
+// trailing slash copies the contents of the volume
+func (tc *templateConfig) getRsyncSource() string {
+	return "rsync://" + tc.rsyncUsername + "@" + tc.name + "." + tc.sourcePVCNamespace + ":" +
+		strconv.Itoa(rsyncPort) + SourcePvcMountPath + "/"
+}
+
+// getRsyncClientJobTemplate returns a job running the rsync client with the
+// given args, having the destination pvc mounted and the password of the daemon
+func (tc *templateConfig) getRsyncClientJobTemplate(name, destinationPVC, role string, args []string) batchv1.Job {
+	backoffLimit := int32(dataSyncBackoffLimit)
+	job := batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: tc.sourcePVCNamespace,
+			Labels: map[string]string{
+				createdByLabel: componentName,
+				managedByLabel: componentName,
+				appLabel:       tc.name,
+				roleLabel:      role,
+			},
+			OwnerReferences: tc.ownerReferences,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						createdByLabel: componentName,
+						managedByLabel: componentName,
+						appLabel:       tc.name,
+						roleLabel:      role,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            role,
+							Image:           RsyncClientImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Args:            args,
+							Env: []corev1.EnvVar{
+								{
+									Name: "RSYNC_PASSWORD",
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{Name: tc.credentialsSecret},
+											Key:                  passwordKey,
+										},
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: dataSyncDestinationPath,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: destinationPVC,
+								},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+	return job
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func (c *controller) handleDataExport(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.exportQueue.Add(key)
+}
+
+func (c *controller) runExportWorker() {
+	c.runQueueWorker(c.exportQueue, c.syncExport)
+}
+
+// runQueueWorker processes the namespace/name keys of the queue using sync
+func (c *controller) runQueueWorker(queue workqueue.RateLimitingInterface,
+	sync func(ctx context.Context, key, namespace, name string) error) {
+	processNext := func(obj interface{}) error {
+		defer queue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			queue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		parts := strings.Split(key, "/")
+		if len(parts) != 2 {
+			utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+			return nil
+		}
+		if err := sync(context.TODO(), key, parts[0], parts[1]); err != nil {
+			queue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		queue.Forget(obj)
+		return nil
+	}
+
+	for {
+		obj, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		if err := processNext(obj); err != nil {
+			utilruntime.HandleError(err)
+		}
+	}
+}
+
+func (c *controller) syncExport(ctx context.Context, key, namespace, name string) error {
+	unstruct, err := c.deLister.Namespace(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The export job is garbage collected with the data export
+			utilruntime.HandleError(fmt.Errorf("data export '%s' in work queue no longer exists", key))
+			return nil
+		}
+		return fmt.Errorf("error getting data export error: %s", err)
+	}
+
+	dataExport := internalv1alpha1.DataExport{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+		&dataExport); err != nil {
+		return fmt.Errorf("error converting data export `%s` in `%s` namespace error: %s",
+			unstruct.GetName(), unstruct.GetNamespace(), err)
+	}
+
+	// If the status is completed or failed then don't perform any action
+	if dataExport.Status.State == internalv1alpha1.StatusCompleted ||
+		dataExport.Status.State == internalv1alpha1.StatusFailed {
+		return nil
+	}
+
+	target, err := getExportTarget(dataExport.Spec.Target)
+	if err != nil {
+		return c.failDataExport(&dataExport, err.Error())
+	}
+	jobTemplate, err := getExportJobTemplate(dataExport)
+	if err != nil {
+		return c.failDataExport(&dataExport, err.Error())
+	}
+
+	// Check whether the source pvc is already created so that the export job can mount it
+	sourcePVC, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).
+		Get(ctx, dataExport.Spec.SourcePVC, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s",
+			dataExport.Spec.SourcePVC, namespace, err)
+	}
+	if sourcePVC.Spec.VolumeMode != nil && *sourcePVC.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return c.failDataExport(&dataExport, "block volumes are not supported by "+DeKind)
+	}
+
+	// Create the export job which copies the data of the source pvc to the target
+	if err := c.ensureJob(true, namespace, &jobTemplate); err != nil {
+		return fmt.Errorf("error ensuring(true) job `%s` in `%s` namespace, error: %s",
+			jobTemplate.GetName(), namespace, err)
+	}
+
+	if dataExport.Status.State == "" {
+		clone := dataExport.DeepCopy()
+		now := metav1.Now()
+		clone.Status.State = internalv1alpha1.StatusInProgress
+		clone.Status.Message = fmt.Sprintf("exporting pvc `%s` to %s", dataExport.Spec.SourcePVC, target)
+		clone.Status.StartTime = &now
+		if err := c.updateDataExport(clone); err != nil {
+			return fmt.Errorf("error updating status of data export `%s` in `%s` namespace, error: %s",
+				dataExport.GetName(), dataExport.GetNamespace(), err)
+		}
+		return nil
+	}
+
+	job, err := c.kubeClient.BatchV1().Jobs(namespace).Get(ctx, jobTemplate.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting job `%s` in `%s` namespace error: %s",
+			jobTemplate.Name, namespace, err)
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			// The failed job is kept so that the logs of its pods can be checked
+			return c.failDataExport(&dataExport, fmt.Sprintf("export job `%s` failed: %s",
+				job.Name, cond.Message))
+		}
+	}
+	if job.Status.Succeeded == 0 {
+		// We'll check the job again on the next resync
+		return nil
+	}
+
+	// Delete the export job once the data has been exported, the source pvc is no longer mounted
+	if err := c.ensureJob(false, namespace, &jobTemplate); err != nil {
+		return fmt.Errorf("error ensuring(false) job `%s` in `%s` namespace, error: %s",
+			jobTemplate.GetName(), namespace, err)
+	}
+
+	clone := dataExport.DeepCopy()
+	now := metav1.Now()
+	clone.Status.State = internalv1alpha1.StatusCompleted
+	clone.Status.Message = fmt.Sprintf("exported pvc `%s` to %s", dataExport.Spec.SourcePVC, target)
+	clone.Status.CompletionTime = &now
+	if err := c.updateDataExport(clone); err != nil {
+		return fmt.Errorf("error updating status of data export `%s` in `%s` namespace, error: %s",
+			dataExport.GetName(), dataExport.GetNamespace(), err)
+	}
+	return nil
+}
+
+// failDataExport marks the data export as failed with the given message
+func (c *controller) failDataExport(de *internalv1alpha1.DataExport, message string) error {
+	clone := de.DeepCopy()
+	clone.Status.State = internalv1alpha1.StatusFailed
+	clone.Status.Message = message
+	if err := c.updateDataExport(clone); err != nil {
+		return fmt.Errorf("error updating status of data export `%s` in `%s` namespace, error: %s",
+			de.GetName(), de.GetNamespace(), err)
+	}
+	return nil
+}
+
+// updateDataExport updates a data export object
+func (c *controller) updateDataExport(de *internalv1alpha1.DataExport) error {
+	deMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(de.DeepCopy())
+	if err != nil {
+		return err
+	}
+
+	deUnstruct := &unstructured.Unstructured{
+		Object: deMap,
+	}
+
+	_, err = c.dynamicClient.Resource(deGVR).Namespace(de.GetNamespace()).
+		Update(context.TODO(), deUnstruct, metav1.UpdateOptions{})
+	return err
+}
+
+/*
+if found and not created by the data-populator then return error
+if want and found return nil
+if !want and !found return nil
+if want and !found -> create return error/nil
+if !want and found -> delete return error/nil
+*/
+func (c *controller) ensureJob(want bool, namespace string, job *batchv1.Job) error {
+	jobClone := job.DeepCopy()
+	found := true
+	obj, err := c.kubeClient.BatchV1().Jobs(namespace).
+		Get(context.TODO(), jobClone.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			found = false
+		} else {
+			return err
+		}
+	}
+	if found && (obj.GetLabels() == nil || obj.GetLabels()[createdByLabel] != componentName) {
+		return fmt.Errorf("job `%s` found but not created by this operator", obj.GetName())
+	}
+	if want && found {
+		return nil
+	}
+	if !want && !found {
+		return nil
+	}
+	if want && !found {
+		_, err := c.kubeClient.BatchV1().Jobs(namespace).
+			Create(context.TODO(), jobClone, metav1.CreateOptions{})
+		return err
+	}
+	if !want && found {
+		// The pods of the job are deleted along with it
+		propagation := metav1.DeletePropagationBackground
+		err := c.kubeClient.BatchV1().Jobs(namespace).
+			Delete(context.TODO(), jobClone.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	"github.com/openebs/data-populator/pkg/rsync"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	// exportBackoffLimit is the number of retries of the export job
+	exportBackoffLimit = 3
+
+	defaultSSHPort        = 22
+	defaultRcloneProvider = "Other"
+)
+
+// exportContainer is the image, args and env of the export container
+type exportContainer struct {
+	image   string
+	args    []string
+	env     []corev1.EnvVar
+	volumes []corev1.Volume
+	mounts  []corev1.VolumeMount
+}
+
+// getExportTarget returns a description of the target of the export
+func getExportTarget(target internalv1alpha1.DataExportTarget) (string, error) {
+	count := 0
+	description := ""
+	if target.Rsync != nil {
+		count++
+		description = "rsync://" + target.Rsync.URL + target.Rsync.Path
+	}
+	if target.SSH != nil {
+		count++
+		description = "ssh://" + target.SSH.User + "@" + target.SSH.Host + ":" + target.SSH.Path
+	}
+	if target.S3 != nil {
+		count++
+		description = "s3://" + target.S3.Bucket + "/" + strings.Trim(target.S3.Prefix, "/")
+	}
+	if count != 1 {
+		return "", fmt.Errorf("exactly one of rsync, ssh or s3 target must be set")
+	}
+	return description, nil
+}
+
+// getExportJobTemplate returns the job exporting the source pvc to the target
+func getExportJobTemplate(de internalv1alpha1.DataExport) (batchv1.Job, error) {
+	var con exportContainer
+	var err error
+	switch target := de.Spec.Target; {
+	case target.Rsync != nil:
+		con, err = getRsyncExportContainer(target.Rsync)
+	case target.SSH != nil:
+		con, err = getSSHExportContainer(target.SSH)
+	case target.S3 != nil:
+		con, err = getS3ExportContainer(target.S3)
+	default:
+		err = fmt.Errorf("no target is set")
+	}
+	if err != nil {
+		return batchv1.Job{}, err
+	}
+
+	backoffLimit := int32(exportBackoffLimit)
+	isController := true
+	job := batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      exportNamePrefix + de.Name,
+			Namespace: de.Namespace,
+			Labels: map[string]string{
+				createdByLabel: componentName,
+				managedByLabel: componentName,
+				roleLabel:      exportRoleLabelValue,
+			},
+			// The job is garbage collected with the export
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+					Kind:       DeKind,
+					Name:       de.Name,
+					UID:        de.UID,
+					Controller: &isController,
+				},
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						createdByLabel: componentName,
+						managedByLabel: componentName,
+						appLabel:       exportNamePrefix + de.Name,
+						roleLabel:      exportRoleLabelValue,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "data-export",
+							Image:           con.image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Args:            con.args,
+							Env:             con.env,
+							VolumeMounts: append([]corev1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: SourcePvcMountPath,
+									ReadOnly:  true,
+								},
+							}, con.mounts...),
+						},
+					},
+					Volumes: append([]corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: de.Spec.SourcePVC,
+									ReadOnly:  true,
+								},
+							},
+						},
+					}, con.volumes...),
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+	return job, nil
+}
+
+func getRsyncExportContainer(target *internalv1alpha1.RsyncExportTarget) (exportContainer, error) {
+	if target.URL == "" || !strings.HasPrefix(target.Path, "/") {
+		return exportContainer{}, fmt.Errorf("url and an absolute path are required for the rsync target")
+	}
+	destination := "rsync://" + target.URL + target.Path
+	if target.Username != "" {
+		destination = "rsync://" + target.Username + "@" + target.URL + target.Path
+	}
+
+	con := exportContainer{image: RsyncClientImage}
+	if target.PasswordSecret != "" {
+		con.env = append(con.env, corev1.EnvVar{
+			Name: "RSYNC_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: target.PasswordSecret},
+					Key:                  "password",
+				},
+			},
+		})
+	}
+
+	// The trailing slash copies the contents of the volume
+	script := &shell.Script{}
+	cmd := append([]string{"rsync", "--verbose"}, rsync.Args(target.Options)...)
+	script.Run(append(cmd, SourcePvcMountPath+"/", destination)...)
+	con.args = script.Args()
+	return con, nil
+}
+
+func getSSHExportContainer(target *internalv1alpha1.SSHExportTarget) (exportContainer, error) {
+	if target.Host == "" || target.User == "" || target.Path == "" || target.CredentialsSecret == "" {
+		return exportContainer{}, fmt.Errorf("host, user, path and credentialsSecret are required for the ssh target")
+	}
+	port := int32(defaultSSHPort)
+	if target.Port != nil {
+		port = *target.Port
+	}
+
+	// The secret is copied as ssh needs the key to be only readable by the user.
+	// The host key must be in known_hosts, unless accepting it on first use is
+	// explicitly allowed.
+	missingKnownHosts := "echo " + shell.Quote("known_hosts is not in the secret "+target.CredentialsSecret) + " >&2; exit 1"
+	if target.InsecureAcceptNewHostKey {
+		missingKnownHosts = "HOST_KEY_CHECKING=accept-new"
+	}
+	sshDir := "/tmp/.ssh"
+	script := &shell.Script{}
+	script.Raw("mkdir -p -m 700 " + sshDir).
+		Raw("cp " + exportSecretMountPath + "/" + corev1.SSHAuthPrivateKey + " " + sshDir + "/id").
+		Raw("chmod 600 " + sshDir + "/id").
+		Raw("HOST_KEY_CHECKING=yes").
+		Raw("if [ -f " + exportSecretMountPath + "/known_hosts ]; then " +
+			"cp " + exportSecretMountPath + "/known_hosts " + sshDir + "/known_hosts; else " + missingKnownHosts + "; fi").
+		Raw("rsync " + strings.Join(append([]string{"--verbose"}, rsync.Args(target.Options)...), " ") +
+			" -e \"ssh -i " + sshDir + "/id -p " + strconv.Itoa(int(port)) +
+			" -o IdentitiesOnly=yes -o UserKnownHostsFile=" + sshDir + "/known_hosts" +
+			" -o StrictHostKeyChecking=$HOST_KEY_CHECKING\" " +
+			shell.Quote(SourcePvcMountPath+"/") + " " + shell.Quote(target.User+"@"+target.Host+":"+target.Path))
+
+	return exportContainer{
+		image: RsyncClientImage,
+		args:  script.Args(),
+		volumes: []corev1.Volume{
+			{
+				Name: "credentials",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: target.CredentialsSecret,
+					},
+				},
+			},
+		},
+		mounts: []corev1.VolumeMount{
+			{
+				Name:      "credentials",
+				MountPath: exportSecretMountPath,
+				ReadOnly:  true,
+			},
+		},
+	}, nil
+}
+
+func getS3ExportContainer(target *internalv1alpha1.S3ExportTarget) (exportContainer, error) {
+	if target.Bucket == "" {
+		return exportContainer{}, fmt.Errorf("bucket is required for the s3 target")
+	}
+	provider := target.Provider
+	if provider == "" {
+		provider = defaultRcloneProvider
+	}
+
+	// The remote is configured using the RCLONE_CONFIG_<remote>_<option>
+	// environment variables so no config file is needed.
+	remoteEnv := func(option string) string {
+		return "RCLONE_CONFIG_" + exportRemoteName + "_" + option
+	}
+	env := []corev1.EnvVar{
+		{Name: remoteEnv("TYPE"), Value: "s3"},
+		{Name: remoteEnv("PROVIDER"), Value: provider},
+	}
+	if target.Endpoint != "" {
+		env = append(env, corev1.EnvVar{Name: remoteEnv("ENDPOINT"), Value: target.Endpoint})
+	}
+	if target.Region != "" {
+		env = append(env, corev1.EnvVar{Name: remoteEnv("REGION"), Value: target.Region})
+	}
+	if target.CredentialsSecret != "" {
+		for _, option := range []string{"ACCESS_KEY_ID", "SECRET_ACCESS_KEY"} {
+			env = append(env, corev1.EnvVar{
+				Name: remoteEnv(option),
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: target.CredentialsSecret},
+						Key:                  "AWS_" + option,
+					},
+				},
+			})
+		}
+	} else {
+		env = append(env, corev1.EnvVar{Name: remoteEnv("ENV_AUTH"), Value: "true"})
+	}
+
+	destination := exportRemoteName + ":" + target.Bucket
+	if keyPrefix := strings.Trim(target.Prefix, "/"); keyPrefix != "" {
+		destination += "/" + keyPrefix
+	}
+	return exportContainer{
+		image: RcloneClientImage,
+		args:  []string{"rclone", "copy", SourcePvcMountPath, destination, "--verbose", "--stats", "30s"},
+		env:   env,
+	}, nil
+}
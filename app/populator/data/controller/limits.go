@@ -0,0 +1,335 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// The concurrency limits of the data populators copying data, a limit which
+// is 0 is not checked
+var (
+	// MaxActiveTransfers is the number of transfers in the cluster
+	MaxActiveTransfers int
+	// MaxSourceNodeTransfers is the number of transfers from the volumes of a node
+	MaxSourceNodeTransfers int
+	// MaxDestinationNodeTransfers is the number of transfers into the volumes of a node
+	MaxDestinationNodeTransfers int
+	// MaxStorageClassTransfers is the number of transfers into the volumes of a storage class
+	MaxStorageClassTransfers int
+	// MaxAggregateBandwidth is the rate in bytes per second, like 100Mi, of
+	// all the transfers, every one of the active transfers has an equal
+	// share of it
+	MaxAggregateBandwidth string
+
+	// maxAggregateBandwidth is MaxAggregateBandwidth in bytes per second
+	maxAggregateBandwidth int64
+)
+
+// transferTracker keeps the transfers started by this controller, as the
+// status of a data populator may not be in the lister yet when the next one
+// is checked against the limits
+type transferTracker struct {
+	sync.Mutex
+	started map[string]internalv1alpha1.TransferStatus
+}
+
+// transferCounts is the number of transfers counted against each of the limits
+type transferCounts struct {
+	total       int
+	source      map[string]int
+	destination map[string]int
+	class       map[string]int
+}
+
+func newTransferCounts() *transferCounts {
+	return &transferCounts{
+		source:      map[string]int{},
+		destination: map[string]int{},
+		class:       map[string]int{},
+	}
+}
+
+func (tc *transferCounts) add(t internalv1alpha1.TransferStatus) {
+	tc.total++
+	if t.SourceNode != "" {
+		tc.source[t.SourceNode]++
+	}
+	if t.DestinationNode != "" {
+		tc.destination[t.DestinationNode]++
+	}
+	if t.StorageClass != "" {
+		tc.class[t.StorageClass]++
+	}
+}
+
+// check returns the limit which is reached by the transfer, it is empty if
+// the transfer can be started
+func (tc *transferCounts) check(t internalv1alpha1.TransferStatus) string {
+	if MaxActiveTransfers > 0 && tc.total >= MaxActiveTransfers {
+		return fmt.Sprintf("limit of %d active transfers is reached", MaxActiveTransfers)
+	}
+	if MaxSourceNodeTransfers > 0 && t.SourceNode != "" && tc.source[t.SourceNode] >= MaxSourceNodeTransfers {
+		return fmt.Sprintf("limit of %d transfers from node `%s` is reached", MaxSourceNodeTransfers, t.SourceNode)
+	}
+	if MaxDestinationNodeTransfers > 0 && t.DestinationNode != "" &&
+		tc.destination[t.DestinationNode] >= MaxDestinationNodeTransfers {
+		return fmt.Sprintf("limit of %d transfers into node `%s` is reached",
+			MaxDestinationNodeTransfers, t.DestinationNode)
+	}
+	if MaxStorageClassTransfers > 0 && t.StorageClass != "" && tc.class[t.StorageClass] >= MaxStorageClassTransfers {
+		return fmt.Sprintf("limit of %d transfers into storage class `%s` is reached",
+			MaxStorageClassTransfers, t.StorageClass)
+	}
+	return ""
+}
+
+// allocateBandwidth returns the bandwidth limit of a transfer, the aggregate
+// bandwidth is divided equally between the active transfers and the share of
+// the transfer is not more than its own limit
+func allocateBandwidth(limit int64, active int) int64 {
+	if maxAggregateBandwidth <= 0 {
+		return limit
+	}
+	if active < 1 {
+		active = 1
+	}
+	share := maxAggregateBandwidth / int64(active)
+	if limit > 0 && limit < share {
+		share = limit
+	}
+	if share < 1 {
+		share = 1
+	}
+	return share
+}
+
+// hasTransferLimits returns true if any of the concurrency limits is set
+func hasTransferLimits() bool {
+	return MaxActiveTransfers > 0 || MaxSourceNodeTransfers > 0 ||
+		MaxDestinationNodeTransfers > 0 || MaxStorageClassTransfers > 0 || maxAggregateBandwidth > 0
+}
+
+// isTransferFinished returns true if the data populator is not copying data anymore
+func isTransferFinished(dp *internalv1alpha1.DataPopulator) bool {
+	return dp.Status.State == internalv1alpha1.StatusCompleted ||
+		dp.Status.State == internalv1alpha1.StatusFailed ||
+		dp.Status.State == internalv1alpha1.StatusReadyForCutover ||
+		dp.Status.State == internalv1alpha1.StatusSuspended
+}
+
+// admitTransfer checks whether the transfer of the data populator can be
+// started. The data populators waiting before it, which fit in the limits,
+// are started first, so it returns the position of the data populator in
+// the queue and the limit it is waiting for, if it can't be started. The
+// bandwidth limit of the transfer is set to its share of the aggregate
+// bandwidth, which is computed again on every sync of a running transfer as
+// the number of active transfers changes.
+func (c *controller) admitTransfer(key string, dp *internalv1alpha1.DataPopulator,
+	transfer *internalv1alpha1.TransferStatus) (bool, int32, string, error) {
+	if !hasTransferLimits() {
+		return true, 0, "", nil
+	}
+	c.transfers.Lock()
+	defer c.transfers.Unlock()
+	_, started := c.transfers.started[key]
+	// The status of the data populator may not be updated with the transfer yet
+	started = started || (dp.Status.Transfer != nil && dp.Status.Transfer.StartTime != nil)
+	if started && maxAggregateBandwidth <= 0 {
+		return true, 0, "", nil
+	}
+
+	objs, err := c.dpLister.List(labels.Everything())
+	if err != nil {
+		return false, 0, "", fmt.Errorf("error listing data populators error: %s", err)
+	}
+	counts := newTransferCounts()
+	queued := []*internalv1alpha1.DataPopulator{}
+	found := map[string]bool{}
+	for _, obj := range objs {
+		other := &internalv1alpha1.DataPopulator{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), other); err != nil {
+			return false, 0, "", fmt.Errorf("error converting data populator `%s` in `%s` namespace error: %s",
+				obj.GetName(), obj.GetNamespace(), err)
+		}
+		otherKey, err := cache.MetaNamespaceKeyFunc(other)
+		if err != nil || otherKey == key || isTransferFinished(other) {
+			continue
+		}
+		found[otherKey] = true
+		if started, ok := c.transfers.started[otherKey]; ok {
+			counts.add(started)
+		} else if other.Status.Transfer != nil && other.Status.Transfer.StartTime != nil {
+			counts.add(*other.Status.Transfer)
+		} else if other.Status.State == internalv1alpha1.StatusPending && other.Status.Transfer != nil {
+			queued = append(queued, other)
+		}
+	}
+	// The transfers of the finished and deleted data populators are not counted
+	for startedKey := range c.transfers.started {
+		if !found[startedKey] {
+			delete(c.transfers.started, startedKey)
+		}
+	}
+
+	// The transfer which is running is not checked against the limits again
+	if started {
+		transfer.BandwidthLimit = allocateBandwidth(transfer.BandwidthLimit, counts.total+1)
+		return true, 0, "", nil
+	}
+
+	sort.Slice(queued, func(i, j int) bool {
+		return isQueuedBefore(queued[i], queued[j])
+	})
+	current := dp.DeepCopy()
+	current.Status.Transfer = transfer
+	active := counts.total + 1
+	position := int32(1)
+	for _, other := range queued {
+		if !isQueuedBefore(other, current) {
+			break
+		}
+		position++
+		if counts.check(*other.Status.Transfer) == "" {
+			counts.add(*other.Status.Transfer)
+		}
+	}
+	if reason := counts.check(*transfer); reason != "" {
+		return false, position, reason, nil
+	}
+	transfer.BandwidthLimit = allocateBandwidth(transfer.BandwidthLimit, active)
+	c.transfers.started[key] = *transfer
+	if maxAggregateBandwidth > 0 {
+		// The running transfers get a smaller share
+		c.requeuePendingTransfers()
+	}
+	return true, 0, "", nil
+}
+
+// isQueuedBefore returns true if the data populator a is started before b,
+// the data populators are started in the order of their priority and then
+// in the order they are created
+func isQueuedBefore(a, b *internalv1alpha1.DataPopulator) bool {
+	if a.Status.Transfer.Priority != b.Status.Transfer.Priority {
+		return a.Status.Transfer.Priority > b.Status.Transfer.Priority
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// getTransferPriority returns the priority of the data populator, from its
+// spec or its priority class
+func (c *controller) getTransferPriority(ctx context.Context, dp *internalv1alpha1.DataPopulator) (int32, error) {
+	if dp.Spec.Priority != nil {
+		return *dp.Spec.Priority, nil
+	}
+	if dp.Spec.PriorityClassName == "" {
+		return 0, nil
+	}
+	pc, err := c.kubeClient.SchedulingV1().PriorityClasses().Get(ctx, dp.Spec.PriorityClassName, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("error getting priority class `%s` error: %s", dp.Spec.PriorityClassName, err)
+	}
+	return pc.Value, nil
+}
+
+// requeuePendingTransfers queues the pending data populators, once a
+// transfer is finished, so that the next ones are started right away. The
+// running ones are queued too when the aggregate bandwidth is shared between
+// them, so that their shares are computed again.
+func (c *controller) requeuePendingTransfers() {
+	if !hasTransferLimits() {
+		return
+	}
+	objs, err := c.dpLister.List(labels.Everything())
+	if err != nil {
+		return
+	}
+	for _, obj := range objs {
+		state, _, _ := unstructured.NestedString(obj.Object, "status", "state")
+		if state != internalv1alpha1.StatusPending &&
+			(state != internalv1alpha1.StatusInProgress || maxAggregateBandwidth <= 0) {
+			continue
+		}
+		if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+			c.workqueue.Add(key)
+		}
+	}
+}
+
+// restartTransferForBandwidth deletes the rsync daemon pods which were started
+// with another bandwidth limit, and the populator pod copying from them, so
+// that they are created again with the bandwidth limit of the transfer. rsync
+// resumes from the data which is already copied. It returns true if any of
+// the pods is deleted.
+func (c *controller) restartTransferForBandwidth(ctx context.Context, source *controller, dptc *templateConfig,
+	destinationPVC *corev1.PersistentVolumeClaim) (bool, error) {
+	want := ""
+	if dptc.bandwidthLimit > 0 {
+		want = strconv.FormatInt(dptc.bandwidthLimit, 10)
+	}
+	daemons := []*templateConfig{dptc}
+	if len(dptc.sources) > 0 {
+		daemons = dptc.sources
+	}
+	restarted := false
+	for _, stc := range daemons {
+		pod, err := source.getTransferPod(ctx, stc.sourcePVCNamespace, stc.name)
+		if err != nil {
+			return false, err
+		}
+		if pod == nil || pod.GetDeletionTimestamp() != nil || pod.GetAnnotations()[bandwidthLimitAnnotation] == want {
+			continue
+		}
+		err = source.kubeClient.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("error deleting pod `%s` in `%s` namespace error: %s",
+				pod.Name, pod.Namespace, err)
+		}
+		restarted = true
+	}
+	if !restarted {
+		return false, nil
+	}
+
+	podName := populatorPodPrefix + string(destinationPVC.UID)
+	err := c.kubeClient.CoreV1().Pods(RsyncPopulatorNamespace).Delete(ctx, podName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("error deleting populator pod `%s` in `%s` namespace error: %s",
+			podName, RsyncPopulatorNamespace, err)
+	}
+	return true, nil
+}
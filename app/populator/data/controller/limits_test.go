@@ -0,0 +1,235 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func TestIsQueuedBefore(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Minute))
+	queued := func(namespace, name string, priority int32, created metav1.Time) *internalv1alpha1.DataPopulator {
+		return &internalv1alpha1.DataPopulator{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, CreationTimestamp: created},
+			Status: internalv1alpha1.DataPopulatorStatus{
+				Transfer: &internalv1alpha1.TransferStatus{Priority: priority},
+			},
+		}
+	}
+	tests := map[string]struct {
+		a, b *internalv1alpha1.DataPopulator
+		want bool
+	}{
+		"higher priority first": {
+			a:    queued("default", "b", 10, later),
+			b:    queued("default", "a", 0, earlier),
+			want: true,
+		},
+		"lower priority after": {
+			a:    queued("default", "a", 0, earlier),
+			b:    queued("default", "b", 10, later),
+			want: false,
+		},
+		"created earlier first": {
+			a:    queued("default", "b", 5, earlier),
+			b:    queued("default", "a", 5, later),
+			want: true,
+		},
+		"created later after": {
+			a:    queued("default", "a", 5, later),
+			b:    queued("default", "b", 5, earlier),
+			want: false,
+		},
+		"by namespace": {
+			a:    queued("a", "b", 0, earlier),
+			b:    queued("b", "a", 0, earlier),
+			want: true,
+		},
+		"by name": {
+			a:    queued("default", "a", 0, earlier),
+			b:    queued("default", "b", 0, earlier),
+			want: true,
+		},
+		"not before itself": {
+			a:    queued("default", "a", 0, earlier),
+			b:    queued("default", "a", 0, earlier),
+			want: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := isQueuedBefore(test.a, test.b); got != test.want {
+				t.Errorf("isQueuedBefore() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAllocateBandwidth(t *testing.T) {
+	defer func(bandwidth int64, transfers int) {
+		maxAggregateBandwidth, MaxActiveTransfers = bandwidth, transfers
+	}(maxAggregateBandwidth, MaxActiveTransfers)
+
+	tests := map[string]struct {
+		aggregate          int64
+		maxActiveTransfers int
+		active             int
+		limit              int64
+		want               int64
+	}{
+		"no aggregate limit keeps the own limit": {
+			aggregate: 0,
+			active:    4,
+			limit:     1024,
+			want:      1024,
+		},
+		"no aggregate limit and no own limit": {
+			aggregate: 0,
+			active:    4,
+			limit:     0,
+			want:      0,
+		},
+		"single active transfer gets all of it": {
+			aggregate:          4096,
+			maxActiveTransfers: 4,
+			active:             1,
+			limit:              0,
+			want:               4096,
+		},
+		"share of the active transfers": {
+			aggregate:          4096,
+			maxActiveTransfers: 8,
+			active:             4,
+			limit:              0,
+			want:               1024,
+		},
+		"no limit of active transfers": {
+			aggregate: 4096,
+			active:    2,
+			limit:     0,
+			want:      2048,
+		},
+		"no active transfers is counted as one": {
+			aggregate: 4096,
+			active:    0,
+			limit:     0,
+			want:      4096,
+		},
+		"own limit less than the share": {
+			aggregate: 4096,
+			active:    4,
+			limit:     512,
+			want:      512,
+		},
+		"own limit more than the share": {
+			aggregate: 4096,
+			active:    4,
+			limit:     2048,
+			want:      1024,
+		},
+		"share is at least one byte": {
+			aggregate: 2,
+			active:    4,
+			limit:     0,
+			want:      1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			maxAggregateBandwidth, MaxActiveTransfers = test.aggregate, test.maxActiveTransfers
+			if got := allocateBandwidth(test.limit, test.active); got != test.want {
+				t.Errorf("allocateBandwidth(%d, %d) = %d, want %d", test.limit, test.active, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTransferCountsCheck(t *testing.T) {
+	defer func(total, source, destination, class int) {
+		MaxActiveTransfers, MaxSourceNodeTransfers = total, source
+		MaxDestinationNodeTransfers, MaxStorageClassTransfers = destination, class
+	}(MaxActiveTransfers, MaxSourceNodeTransfers, MaxDestinationNodeTransfers, MaxStorageClassTransfers)
+
+	running := []internalv1alpha1.TransferStatus{
+		{SourceNode: "node-1", DestinationNode: "node-2", StorageClass: "fast"},
+		{SourceNode: "node-1", DestinationNode: "node-3", StorageClass: "slow"},
+	}
+	tests := map[string]struct {
+		total, source, destination, class int
+		transfer                          internalv1alpha1.TransferStatus
+		wantReached                       bool
+	}{
+		"no limits": {
+			transfer: internalv1alpha1.TransferStatus{SourceNode: "node-1"},
+		},
+		"active transfers reached": {
+			total:       2,
+			transfer:    internalv1alpha1.TransferStatus{SourceNode: "node-4"},
+			wantReached: true,
+		},
+		"active transfers not reached": {
+			total:    3,
+			transfer: internalv1alpha1.TransferStatus{SourceNode: "node-4"},
+		},
+		"source node reached": {
+			source:      2,
+			transfer:    internalv1alpha1.TransferStatus{SourceNode: "node-1"},
+			wantReached: true,
+		},
+		"other source node": {
+			source:   2,
+			transfer: internalv1alpha1.TransferStatus{SourceNode: "node-2"},
+		},
+		"unknown source node is not counted": {
+			source:   1,
+			transfer: internalv1alpha1.TransferStatus{},
+		},
+		"destination node reached": {
+			destination: 1,
+			transfer:    internalv1alpha1.TransferStatus{DestinationNode: "node-2"},
+			wantReached: true,
+		},
+		"storage class reached": {
+			class:       1,
+			transfer:    internalv1alpha1.TransferStatus{StorageClass: "slow"},
+			wantReached: true,
+		},
+		"other storage class": {
+			class:    1,
+			transfer: internalv1alpha1.TransferStatus{StorageClass: "standard"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			MaxActiveTransfers, MaxSourceNodeTransfers = test.total, test.source
+			MaxDestinationNodeTransfers, MaxStorageClassTransfers = test.destination, test.class
+			counts := newTransferCounts()
+			for _, transfer := range running {
+				counts.add(transfer)
+			}
+			if reason := counts.check(test.transfer); (reason != "") != test.wantReached {
+				t.Errorf("check() = %q, want reached %t", reason, test.wantReached)
+			}
+		})
+	}
+}
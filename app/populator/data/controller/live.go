@@ -0,0 +1,547 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	"github.com/openebs/data-populator/pkg/rsync"
+)
+
+const (
+	defaultLiveDeltaThreshold = "100Mi"
+	defaultLiveMaxPasses      = 5
+
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+	replicaSetKind  = "ReplicaSet"
+)
+
+// syncLiveMigration copies the data of the source pvc into the destination
+// pvc in passes while the application is running. Once a pass copies only
+// a few changes, the application is scaled down and a final pass is run.
+func (c *controller) syncLiveMigration(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	tc *templateConfig) error {
+	namespace := dp.Namespace
+	status := dp.Status.DeepCopy()
+	if status.Live == nil {
+		status.Live = &internalv1alpha1.LiveMigrationStatus{Phase: internalv1alpha1.LivePhaseCopying}
+	}
+	live := status.Live
+
+	if dp.Spec.SourceCluster != nil {
+		return c.failLiveMigration(ctx, dp, status, "live migration is not supported with a source cluster")
+	}
+	threshold, err := getLiveDeltaThreshold(dp.Spec.Live)
+	if err != nil {
+		return c.failLiveMigration(ctx, dp, status, err.Error())
+	}
+	maxPasses := dp.Spec.Live.MaxPasses
+	if maxPasses <= 0 {
+		maxPasses = defaultLiveMaxPasses
+	}
+
+	// The first pass waits for the transfer window and the concurrency limits,
+	// the same as the transfers of the rsync populator
+	if status.Transfer == nil || status.Transfer.StartTime == nil {
+		admitted, err := c.admitLiveMigration(ctx, dp, status, tc)
+		if err != nil || !admitted {
+			return err
+		}
+	}
+	// The rsync daemon sends the data at the share of the transfer of the aggregate bandwidth
+	tc.bandwidthLimit = status.Transfer.BandwidthLimit
+
+	// The destination pvc is written by the pass jobs, not by the rsync populator
+	destinationPVC := tc.getDestinationPVCTemplate()
+	destinationPVC.Spec.DataSourceRef = nil
+	if err := c.ensurePVC(true, namespace, &destinationPVC); err != nil {
+		return fmt.Errorf("error ensuring pvc(true) `%s` in `%s` namespace, error: %s",
+			destinationPVC.GetName(), namespace, err)
+	}
+
+	// The daemon runs on the node where the source pvc is in use, as the
+	// application keeps running while the data is copied.
+	tc.credentialsSecret = tc.name
+	tc.readOnly = true
+	consumers, err := c.getPVCConsumers(ctx, tc.sourcePVCNamespace, tc.sourcePVCName, tc.name)
+	if err != nil {
+		return err
+	}
+	if len(consumers) > 0 {
+		tc.nodeName = consumers[0].Spec.NodeName
+	}
+	if live.ActiveJob == "" {
+		if err := c.ensureDaemonNode(ctx, tc); err != nil {
+			return err
+		}
+	}
+	credentials, err := c.ensureCredentialsSecret(ctx, tc, "")
+	if err != nil {
+		return fmt.Errorf("error ensuring credentials secret `%s` in `%s` namespace, error: %s",
+			tc.credentialsSecret, tc.sourcePVCNamespace, err)
+	}
+	if namespace != tc.sourcePVCNamespace {
+		// The pass jobs run in the namespace of the destination pvc
+		if err := c.ensureConnectionSecret(ctx, namespace, tc.credentialsSecret, nil,
+			map[string][]byte{passwordKey: credentials[passwordKey]}); err != nil {
+			return fmt.Errorf("error ensuring secret `%s` in `%s` namespace, error: %s",
+				tc.credentialsSecret, namespace, err)
+		}
+	}
+	if err := c.ensureRsyncDaemon(true, tc, tc.sourcePVCNamespace); err != nil {
+		return err
+	}
+
+	// Record the result of the running pass once its job is done
+	if live.ActiveJob != "" {
+		done, failed, bytes, err := c.getRsyncJobResult(ctx, namespace, live.ActiveJob)
+		if err != nil || !done {
+			return err
+		}
+		if failed {
+			return c.failLiveMigration(ctx, dp, status, fmt.Sprintf("pass job `%s` failed", live.ActiveJob))
+		}
+		live.ActiveJob = ""
+		live.Passes++
+		live.LastPassBytes = bytes
+
+		if live.Phase == internalv1alpha1.LivePhaseFinalSync {
+			if err := c.completeLiveMigration(ctx, dp, tc, status); err != nil {
+				return err
+			}
+			c.requeuePendingTransfers()
+			return nil
+		}
+		if bytes < threshold.Value() || live.Passes >= maxPasses {
+			live.Phase = internalv1alpha1.LivePhaseQuiescing
+		}
+	}
+
+	if live.Phase == internalv1alpha1.LivePhaseQuiescing {
+		quiesced, failure, err := c.quiesceWorkloads(ctx, dp, status, consumers)
+		if err != nil {
+			return err
+		}
+		if failure != "" {
+			return c.failLiveMigration(ctx, dp, status, failure)
+		}
+		if !quiesced {
+			return c.updateLiveMigrationStatus(dp, status, "scaling down the workloads using pvc `"+
+				tc.sourcePVCName+"`")
+		}
+		live.Phase = internalv1alpha1.LivePhaseFinalSync
+	}
+
+	// The next pass is not started while the data populator is suspended or
+	// outside the transfer window, the final pass is run once the workloads
+	// are scaled down
+	if live.ActiveJob == "" && live.Phase == internalv1alpha1.LivePhaseCopying {
+		held, err := c.holdLivePass(ctx, dp, status, tc)
+		if err != nil || held {
+			return err
+		}
+	}
+
+	if live.ActiveJob == "" {
+		pod, err := c.kubeClient.CoreV1().Pods(tc.sourcePVCNamespace).Get(ctx, tc.name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error getting pod `%s` in `%s` namespace error: %s",
+				tc.name, tc.sourcePVCNamespace, err)
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			// We'll check the pod again on the next resync
+			return c.updateLiveMigrationStatus(dp, status, "waiting for rsync daemon `"+tc.name+"` to be running")
+		}
+
+		// The passes delete the files which are deleted from the source since
+		// the last pass, and keep the times so that the unchanged files are skipped
+		args := append(getDeleteRsyncArgs(tc.rsyncOptions), rsync.FilterArgs(tc.include, tc.exclude)...)
+		job := tc.getRsyncClientJobTemplate(tc.name+"-pass-"+strconv.Itoa(int(live.Passes)+1),
+			destinationPVC.Name, liveRoleLabelValue, tc.getIncrementalSyncArgs(args...))
+		job.Namespace = namespace
+		ttl := int32(finishedJobTTL.Seconds())
+		job.Spec.TTLSecondsAfterFinished = &ttl
+		if err := c.ensureJob(true, namespace, &job); err != nil {
+			return fmt.Errorf("error ensuring(true) job `%s` in `%s` namespace, error: %s",
+				job.GetName(), namespace, err)
+		}
+		live.ActiveJob = job.Name
+	}
+
+	message := fmt.Sprintf("running pass %d using job `%s`", live.Passes+1, live.ActiveJob)
+	if live.Phase == internalv1alpha1.LivePhaseFinalSync {
+		message = "running the final pass using job `" + live.ActiveJob + "`"
+	}
+	return c.updateLiveMigrationStatus(dp, status, message)
+}
+
+// quiesceWorkloads scales down the workloads of the pods using the source pvc,
+// it returns true once none of the pods are running, or the reason if a pod
+// can't be stopped by scaling down its workload. The replicas of a
+// workload are recorded in the status before it is scaled down on the next
+// sync, so that they can be restored.
+func (c *controller) quiesceWorkloads(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	status *internalv1alpha1.DataPopulatorStatus, consumers []corev1.Pod) (bool, string, error) {
+	namespace := dp.Spec.SourcePVCNamespace
+	recorded := false
+	for _, pod := range consumers {
+		kind, name, err := c.getPodWorkload(ctx, &pod)
+		if err != nil {
+			return false, "", err
+		}
+		if kind == "" {
+			return false, fmt.Sprintf("pod `%s` using the source pvc is not owned by a deployment "+
+				"or statefulset, it can't be scaled down", pod.Name), nil
+		}
+		if findScaledWorkload(status.Live.Workloads, kind, name) != nil {
+			continue
+		}
+		scale, err := c.getScale(ctx, namespace, kind, name)
+		if err != nil {
+			return false, "", err
+		}
+		status.Live.Workloads = append(status.Live.Workloads, internalv1alpha1.ScaledWorkload{
+			Kind:     kind,
+			Name:     name,
+			Replicas: scale.Spec.Replicas,
+		})
+		recorded = true
+	}
+	if recorded {
+		return false, "", nil
+	}
+
+	for _, workload := range status.Live.Workloads {
+		scale, err := c.getScale(ctx, namespace, workload.Kind, workload.Name)
+		if err != nil {
+			return false, "", err
+		}
+		if scale.Spec.Replicas == 0 {
+			continue
+		}
+		scale.Spec.Replicas = 0
+		if err := c.updateScale(ctx, namespace, workload.Kind, scale); err != nil {
+			return false, "", err
+		}
+	}
+	// We'll check the pods again on the next resync
+	return len(consumers) == 0, "", nil
+}
+
+// getPodWorkload returns the kind and name of the deployment or statefulset
+// of the pod, the kind is empty if the pod is not owned by one of them
+func (c *controller) getPodWorkload(ctx context.Context, pod *corev1.Pod) (string, string, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner != nil && owner.Kind == statefulSetKind {
+		return statefulSetKind, owner.Name, nil
+	}
+	if owner != nil && owner.Kind == replicaSetKind {
+		rs, err := c.kubeClient.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("error getting replicaset `%s` in `%s` namespace error: %s",
+				owner.Name, pod.Namespace, err)
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == deploymentKind {
+			return deploymentKind, rsOwner.Name, nil
+		}
+	}
+	return "", "", nil
+}
+
+// restoreWorkloads scales up the workloads to the replicas they had before
+// they were scaled down
+func (c *controller) restoreWorkloads(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	status *internalv1alpha1.DataPopulatorStatus) error {
+	for _, workload := range status.Live.Workloads {
+		scale, err := c.getScale(ctx, dp.Spec.SourcePVCNamespace, workload.Kind, workload.Name)
+		if err != nil {
+			return err
+		}
+		scale.Spec.Replicas = workload.Replicas
+		if err := c.updateScale(ctx, dp.Spec.SourcePVCNamespace, workload.Kind, scale); err != nil {
+			return err
+		}
+	}
+	status.Live.Workloads = nil
+	return nil
+}
+
+func (c *controller) getScale(ctx context.Context, namespace, kind, name string) (*autoscalingv1.Scale, error) {
+	var scale *autoscalingv1.Scale
+	var err error
+	switch kind {
+	case deploymentKind:
+		scale, err = c.kubeClient.AppsV1().Deployments(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	case statefulSetKind:
+		scale, err = c.kubeClient.AppsV1().StatefulSets(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	default:
+		err = fmt.Errorf("unsupported kind")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting scale of %s `%s` in `%s` namespace error: %s",
+			strings.ToLower(kind), name, namespace, err)
+	}
+	return scale, nil
+}
+
+func (c *controller) updateScale(ctx context.Context, namespace, kind string, scale *autoscalingv1.Scale) error {
+	var err error
+	switch kind {
+	case deploymentKind:
+		_, err = c.kubeClient.AppsV1().Deployments(namespace).UpdateScale(ctx, scale.Name, scale, metav1.UpdateOptions{})
+	case statefulSetKind:
+		_, err = c.kubeClient.AppsV1().StatefulSets(namespace).UpdateScale(ctx, scale.Name, scale, metav1.UpdateOptions{})
+	default:
+		err = fmt.Errorf("unsupported kind")
+	}
+	if err != nil {
+		return fmt.Errorf("error scaling %s `%s` in `%s` namespace to %d replicas error: %s",
+			strings.ToLower(kind), scale.Name, namespace, scale.Spec.Replicas, err)
+	}
+	return nil
+}
+
+func findScaledWorkload(workloads []internalv1alpha1.ScaledWorkload, kind, name string) *internalv1alpha1.ScaledWorkload {
+	for i := range workloads {
+		if workloads[i].Kind == kind && workloads[i].Name == name {
+			return &workloads[i]
+		}
+	}
+	return nil
+}
+
+// completeLiveMigration deletes the rsync daemon once the final pass is done,
+// the workloads are kept scaled down till they are pointed to the destination pvc
+func (c *controller) completeLiveMigration(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	tc *templateConfig, status *internalv1alpha1.DataPopulatorStatus) error {
+	if err := c.deleteLiveRsyncDaemon(ctx, dp, tc); err != nil {
+		return err
+	}
+
+	workloads := []string{}
+	for _, workload := range status.Live.Workloads {
+		workloads = append(workloads, strings.ToLower(workload.Kind)+" `"+workload.Name+"`")
+	}
+	message := "ready for cutover, "
+	if len(workloads) > 0 {
+		message += "point " + strings.Join(workloads, ", ") + " to "
+	} else {
+		message += "use "
+	}
+	message += "pvc `" + tc.getDestinationPVCTemplate().Name + "`"
+
+	status.State = internalv1alpha1.StatusReadyForCutover
+	return c.updateLiveMigrationStatus(dp, status, message)
+}
+
+// failLiveMigration restores the workloads and marks the data populator as failed
+func (c *controller) failLiveMigration(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	status *internalv1alpha1.DataPopulatorStatus, message string) error {
+	if err := c.restoreWorkloads(ctx, dp, status); err != nil {
+		return err
+	}
+	if dp.Spec.SourceCluster == nil {
+		tc, err := templateFromDataPopulator(*dp)
+		if err != nil {
+			return fmt.Errorf("error creating template config error: %s", err)
+		}
+		if err := c.deleteLiveRsyncDaemon(ctx, dp, tc); err != nil {
+			// The failed jobs are kept for their logs, the daemon can be deleted by hand
+			klog.Errorf("error deleting rsync daemon `%s` of data populator `%s`: %s", tc.name, dp.Name, err)
+		}
+	}
+	status.State = internalv1alpha1.StatusFailed
+	if err := c.updateLiveMigrationStatus(dp, status, message); err != nil {
+		return err
+	}
+	c.requeuePendingTransfers()
+	return nil
+}
+
+// admitLiveMigration checks whether the first pass of the live migration can
+// be started, in the transfer window and within the concurrency limits, and
+// records its transfer. The status is updated with what it is waiting for
+// if it can't be started.
+func (c *controller) admitLiveMigration(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	status *internalv1alpha1.DataPopulatorStatus, tc *templateConfig) (bool, error) {
+	if dp.Spec.Suspend {
+		status.State = internalv1alpha1.StatusSuspended
+		status.QueuePosition = 0
+		status.Transfer = nil
+		return false, c.updateLiveMigrationStatus(dp, status, "suspended, no pass is started till it is resumed")
+	}
+	key, err := cache.MetaNamespaceKeyFunc(dp)
+	if err != nil {
+		return false, err
+	}
+
+	status.Window = nil
+	if window := getTransferWindow(dp); window != nil {
+		now := time.Now()
+		windowStatus, err := getTransferWindowStatus(window, now)
+		if err != nil {
+			return false, c.failLiveMigration(ctx, dp, status, err.Error())
+		}
+		// We'll check the window again when it opens or closes
+		c.workqueue.AddAfter(key, windowStatus.NextTransition.Sub(now))
+		status.Window = windowStatus
+		if !windowStatus.Open {
+			status.State = internalv1alpha1.StatusPending
+			status.QueuePosition = 0
+			status.Transfer = nil
+			return false, c.updateLiveMigrationStatus(dp, status, "waiting for the transfer window, opens at "+
+				windowStatus.NextTransition.Format(time.RFC3339))
+		}
+	}
+
+	// The node of the destination volume is not known till the first pass
+	// job is scheduled
+	sourcePVC, err := c.kubeClient.CoreV1().PersistentVolumeClaims(tc.sourcePVCNamespace).
+		Get(ctx, tc.sourcePVCName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s",
+			tc.sourcePVCName, tc.sourcePVCNamespace, err)
+	}
+	transfer := internalv1alpha1.TransferStatus{
+		SourceNode:     sourcePVC.GetAnnotations()[nodeNameAnnotation],
+		BandwidthLimit: tc.bandwidthLimit,
+	}
+	if tc.destinationPVCSpec.StorageClassName != nil {
+		transfer.StorageClass = *tc.destinationPVCSpec.StorageClassName
+	}
+	transfer.Priority, err = c.getTransferPriority(ctx, dp)
+	if err != nil {
+		return false, err
+	}
+	admitted, position, reason, err := c.admitTransfer(key, dp, &transfer)
+	if err != nil {
+		return false, err
+	}
+	if !admitted {
+		status.State = internalv1alpha1.StatusPending
+		status.QueuePosition = position
+		status.Transfer = &transfer
+		return false, c.updateLiveMigrationStatus(dp, status, "waiting for a transfer to finish, "+reason)
+	}
+
+	now := metav1.Now()
+	transfer.StartTime = &now
+	status.State = internalv1alpha1.StatusInProgress
+	status.QueuePosition = 0
+	status.Transfer = &transfer
+	return true, nil
+}
+
+// holdLivePass returns true if the next pass of the live migration is held
+// back, while the data populator is suspended or paused outside the transfer
+// window. A suspended live migration is checked against the concurrency
+// limits again when it is resumed.
+func (c *controller) holdLivePass(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	status *internalv1alpha1.DataPopulatorStatus, tc *templateConfig) (bool, error) {
+	if dp.Spec.Suspend {
+		if err := c.ensureRsyncDaemon(false, tc, tc.sourcePVCNamespace); err != nil {
+			return false, err
+		}
+		status.State = internalv1alpha1.StatusSuspended
+		status.QueuePosition = 0
+		status.Transfer = nil
+		if err := c.updateLiveMigrationStatus(dp, status, "suspended, no pass is started till it is resumed"); err != nil {
+			return false, err
+		}
+		c.requeuePendingTransfers()
+		return true, nil
+	}
+
+	window := getTransferWindow(dp)
+	if window == nil {
+		status.State = internalv1alpha1.StatusInProgress
+		status.Window = nil
+		return false, nil
+	}
+	key, err := cache.MetaNamespaceKeyFunc(dp)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	windowStatus, err := getTransferWindowStatus(window, now)
+	if err != nil {
+		return false, c.failLiveMigration(ctx, dp, status, err.Error())
+	}
+	// We'll check the window again when it opens or closes
+	c.workqueue.AddAfter(key, windowStatus.NextTransition.Sub(now))
+	status.Window = windowStatus
+	if !windowStatus.Open && window.PauseOutsideWindow {
+		status.State = internalv1alpha1.StatusPaused
+		return true, c.updateLiveMigrationStatus(dp, status, "paused outside the transfer window, resumes at "+
+			windowStatus.NextTransition.Format(time.RFC3339))
+	}
+	status.State = internalv1alpha1.StatusInProgress
+	return false, nil
+}
+
+// deleteLiveRsyncDaemon deletes the rsync daemon and its secrets
+func (c *controller) deleteLiveRsyncDaemon(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	tc *templateConfig) error {
+	if err := c.ensureRsyncDaemon(false, tc, tc.sourcePVCNamespace); err != nil {
+		return err
+	}
+	for _, namespace := range []string{tc.sourcePVCNamespace, dp.Namespace} {
+		secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tc.name}}
+		if err := c.ensureSecret(false, namespace, &secret); err != nil {
+			return fmt.Errorf("error ensuring(false) secret `%s` in `%s` namespace, error: %s",
+				secret.Name, namespace, err)
+		}
+	}
+	return nil
+}
+
+// updateLiveMigrationStatus updates the status of the data populator, if it is changed
+func (c *controller) updateLiveMigrationStatus(dp *internalv1alpha1.DataPopulator,
+	status *internalv1alpha1.DataPopulatorStatus, message string) error {
+	clone := dp.DeepCopy()
+	clone.Status = *status.DeepCopy()
+	clone.Status.Message = message
+	if equality.Semantic.DeepEqual(clone.Status, dp.Status) {
+		return nil
+	}
+	if err := c.updateDataPopulator(clone); err != nil {
+		return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
+			dp.GetName(), dp.GetNamespace(), err)
+	}
+	return nil
+}
+
+func getLiveDeltaThreshold(live *internalv1alpha1.LiveMigration) (resource.Quantity, error) {
+	if live.DeltaThreshold != nil {
+		return *live.DeltaThreshold, nil
+	}
+	return resource.ParseQuantity(defaultLiveDeltaThreshold)
+}
@@ -0,0 +1,549 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+const (
+	// pvcExportUsername is the rsync user of the pvc exports
+	pvcExportUsername = "openebs-user"
+	// The certificate of the daemon is signed by a CA generated for it, the
+	// clients trust only this CA. The certificate is renewed when it is
+	// close to its expiry, along with the CA if it expires before the
+	// renewed certificate.
+	pvcExportCACertValidity    = 365 * 24 * time.Hour
+	pvcExportCertValidity      = 90 * 24 * time.Hour
+	pvcExportCertRenewalPeriod = 30 * 24 * time.Hour
+	// caPrivateKeyKey is the key of the credentials secret having the
+	// private key of the CA
+	caPrivateKeyKey = "ca.key"
+)
+
+func (c *controller) handlePVCExport(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.pvcExportQueue.Add(key)
+}
+
+func (c *controller) runPVCExportWorker() {
+	c.runQueueWorker(c.pvcExportQueue, c.syncPVCExport)
+}
+
+func (c *controller) syncPVCExport(ctx context.Context, key, namespace, name string) error {
+	unstruct, err := c.peLister.Namespace(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The rsync daemon resources are garbage collected with the pvc export
+			utilruntime.HandleError(fmt.Errorf("pvc export '%s' in work queue no longer exists", key))
+			return nil
+		}
+		return fmt.Errorf("error getting pvc export error: %s", err)
+	}
+
+	pvcExport := internalv1alpha1.PVCExport{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+		&pvcExport); err != nil {
+		return fmt.Errorf("error converting pvc export `%s` in `%s` namespace error: %s",
+			unstruct.GetName(), unstruct.GetNamespace(), err)
+	}
+
+	if pvcExport.Status.State == internalv1alpha1.StatusFailed {
+		return nil
+	}
+
+	// Check whether the source pvc is already created so that rsync daemon can work properly
+	sourcePVC, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).
+		Get(ctx, pvcExport.Spec.SourcePVC, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s",
+			pvcExport.Spec.SourcePVC, namespace, err)
+	}
+	if sourcePVC.Spec.VolumeMode != nil && *sourcePVC.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return c.updatePVCExportStatus(&pvcExport, internalv1alpha1.StatusFailed,
+			"block volumes are not supported by "+PeKind, "")
+	}
+
+	tc := templateFromPVCExport(pvcExport)
+	for _, r := range tc.allowedSourceRanges {
+		if _, _, err := net.ParseCIDR(r); err != nil {
+			return c.updatePVCExportStatus(&pvcExport, internalv1alpha1.StatusFailed,
+				"invalid source range `"+r+"`, it must be a CIDR", "")
+		}
+	}
+	if tc.tls && len(tc.allowedSourceRanges) > 0 && tc.serviceType != corev1.ServiceTypeLoadBalancer {
+		return c.updatePVCExportStatus(&pvcExport, internalv1alpha1.StatusFailed,
+			"allowed source ranges with tls need a LoadBalancer service", "")
+	}
+
+	// The password and the certificate are generated once and kept in the credentials secret
+	credentials, err := c.ensureCredentialsSecret(ctx, tc, pvcExport.Spec.Address)
+	if err != nil {
+		return fmt.Errorf("error ensuring credentials secret `%s` in `%s` namespace, error: %s",
+			tc.credentialsSecret, namespace, err)
+	}
+
+	// Create all the resources needed for the rsync daemon to be up and running
+	if err := c.ensureRsyncDaemon(true, tc, namespace); err != nil {
+		return err
+	}
+
+	url, err := c.getPVCExportURL(ctx, tc, pvcExport.Spec.Address)
+	if err != nil {
+		return err
+	}
+	if url == "" {
+		// We'll check the address again on the next resync
+		return c.updatePVCExportStatus(&pvcExport, internalv1alpha1.StatusInProgress,
+			"waiting for the address of service `"+tc.name+"`", "")
+	}
+
+	connection := map[string][]byte{
+		urlKey:      []byte(url),
+		pathKey:     []byte(SourcePvcMountPath),
+		usernameKey: []byte(tc.rsyncUsername),
+		passwordKey: credentials[passwordKey],
+	}
+	if tc.tls {
+		connection[caKey] = credentials[caKey]
+	}
+	connectionSecret := getPVCExportConnectionSecretName(pvcExport)
+	if err := c.ensureConnectionSecret(ctx, namespace, connectionSecret, tc.ownerReferences,
+		connection); err != nil {
+		return fmt.Errorf("error ensuring connection secret `%s` in `%s` namespace, error: %s",
+			connectionSecret, namespace, err)
+	}
+
+	return c.updatePVCExportStatus(&pvcExport, internalv1alpha1.StatusReady,
+		"connection details are in secret `"+connectionSecret+"`", url)
+}
+
+func templateFromPVCExport(pe internalv1alpha1.PVCExport) *templateConfig {
+	serviceType := pe.Spec.ServiceType
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeLoadBalancer
+	}
+	isController := true
+	// The daemon is reached from outside the cluster, so it is wrapped with
+	// tls unless it is turned off
+	tls := pe.Spec.TLS == nil || *pe.Spec.TLS
+	return &templateConfig{
+		name:                pvcExportNamePrefix + pe.Name,
+		sourcePVCName:       pe.Spec.SourcePVC,
+		sourcePVCNamespace:  pe.Namespace,
+		imageName:           RsyncServerImage,
+		rsyncUsername:       pvcExportUsername,
+		credentialsSecret:   pvcExportNamePrefix + pe.Name,
+		readOnly:            true,
+		tls:                 tls,
+		serviceType:         serviceType,
+		allowedSourceRanges: pe.Spec.AllowedSourceRanges,
+		// The resources are garbage collected with the pvc export
+		ownerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+				Kind:       PeKind,
+				Name:       pe.Name,
+				UID:        pe.UID,
+				Controller: &isController,
+			},
+		},
+	}
+}
+
+func getPVCExportConnectionSecretName(pe internalv1alpha1.PVCExport) string {
+	if pe.Spec.ConnectionSecret != "" {
+		return pe.Spec.ConnectionSecret
+	}
+	return pe.Name + "-connection"
+}
+
+// getPVCExportURL returns the address and port to reach the rsync daemon from
+// outside the cluster, it is empty if the address is not yet known.
+func (c *controller) getPVCExportURL(ctx context.Context, tc *templateConfig, address string) (string, error) {
+	svc, err := c.kubeClient.CoreV1().Services(tc.sourcePVCNamespace).Get(ctx, tc.name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting service `%s` in `%s` namespace error: %s",
+			tc.name, tc.sourcePVCNamespace, err)
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return "", nil
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		if address == "" {
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				if ingress.IP != "" {
+					address = ingress.IP
+				} else {
+					address = ingress.Hostname
+				}
+				break
+			}
+		}
+		if address == "" {
+			return "", nil
+		}
+		return net.JoinHostPort(address, strconv.Itoa(int(svc.Spec.Ports[0].Port))), nil
+	}
+
+	nodePort := svc.Spec.Ports[0].NodePort
+	if nodePort == 0 {
+		return "", nil
+	}
+	if address == "" {
+		// Use the address of the node running the rsync daemon
+		pod, err := c.kubeClient.CoreV1().Pods(tc.sourcePVCNamespace).Get(ctx, tc.name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error getting pod `%s` in `%s` namespace error: %s",
+				tc.name, tc.sourcePVCNamespace, err)
+		}
+		if pod.Spec.NodeName == "" {
+			return "", nil
+		}
+		node, err := c.kubeClient.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error getting node `%s` error: %s", pod.Spec.NodeName, err)
+		}
+		address = getNodeAddress(node)
+	}
+	if address == "" {
+		return "", nil
+	}
+	return net.JoinHostPort(address, strconv.Itoa(int(nodePort))), nil
+}
+
+// getNodeAddress returns the external address of the node, or its internal
+// address if it has no external address.
+func getNodeAddress(node *corev1.Node) string {
+	internal := ""
+	for _, addr := range node.Status.Addresses {
+		switch addr.Type {
+		case corev1.NodeExternalIP:
+			return addr.Address
+		case corev1.NodeInternalIP:
+			if internal == "" {
+				internal = addr.Address
+			}
+		}
+	}
+	return internal
+}
+
+// ensureCredentialsSecret creates the secret having the password of the rsync
+// user and the tls certificate of the daemon, if it is not already created.
+// The certificate is renewed when it is close to its expiry.
+func (c *controller) ensureCredentialsSecret(ctx context.Context, tc *templateConfig,
+	address string) (map[string][]byte, error) {
+	now := time.Now()
+	obj, err := c.kubeClient.CoreV1().Secrets(tc.sourcePVCNamespace).
+		Get(ctx, tc.credentialsSecret, metav1.GetOptions{})
+	if err == nil {
+		if obj.GetLabels() == nil || obj.GetLabels()[createdByLabel] != componentName {
+			return nil, fmt.Errorf("secret `%s` found but not created by this operator", obj.GetName())
+		}
+		if !tc.tls || !needsCertificate(obj.Data, now) {
+			return obj.Data, nil
+		}
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	found := err == nil
+	data := map[string][]byte{}
+	if found {
+		for k, v := range obj.Data {
+			data[k] = v
+		}
+	}
+	if _, ok := data[passwordKey]; !ok {
+		password, err := generatePassword()
+		if err != nil {
+			return nil, err
+		}
+		data[passwordKey] = []byte(password)
+	}
+	if tc.tls {
+		if err := generateCertificate(tc, address, data, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if found {
+		clone := obj.DeepCopy()
+		clone.Data = data
+		_, err = c.kubeClient.CoreV1().Secrets(tc.sourcePVCNamespace).Update(ctx, clone, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := obj.Data[corev1.TLSCertKey]; ok {
+			// stunnel reads the certificate only when it starts, the pod of
+			// the daemon is created again with the renewed certificate
+			pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: tc.name}}
+			if err := c.ensurePod(false, tc.sourcePVCNamespace, &pod); err != nil {
+				return nil, fmt.Errorf("error ensuring(false) pod `%s` in `%s` namespace, error: %s",
+					tc.name, tc.sourcePVCNamespace, err)
+			}
+		}
+		return data, nil
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: tc.credentialsSecret,
+			Labels: map[string]string{
+				createdByLabel: componentName,
+				managedByLabel: componentName,
+				roleLabel:      roleLabelValue,
+			},
+			OwnerReferences: tc.ownerReferences,
+		},
+		Data: data,
+	}
+	_, err = c.kubeClient.CoreV1().Secrets(tc.sourcePVCNamespace).Create(ctx, secret, metav1.CreateOptions{})
+	return data, err
+}
+
+// ensureConnectionSecret creates or updates the connection secret with the given data
+func (c *controller) ensureConnectionSecret(ctx context.Context, namespace, name string,
+	ownerReferences []metav1.OwnerReference, data map[string][]byte) error {
+	obj, err := c.kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					createdByLabel: componentName,
+					managedByLabel: componentName,
+				},
+				OwnerReferences: ownerReferences,
+			},
+			Data: data,
+		}
+		_, err = c.kubeClient.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	}
+	if obj.GetLabels() == nil || obj.GetLabels()[createdByLabel] != componentName {
+		return fmt.Errorf("secret `%s` found but not created by this operator", obj.GetName())
+	}
+	if reflect.DeepEqual(obj.Data, data) {
+		return nil
+	}
+	clone := obj.DeepCopy()
+	clone.Data = data
+	_, err = c.kubeClient.CoreV1().Secrets(namespace).Update(ctx, clone, metav1.UpdateOptions{})
+	return err
+}
+
+// updatePVCExportStatus updates the status of the pvc export, if it is changed
+func (c *controller) updatePVCExportStatus(pe *internalv1alpha1.PVCExport, state, message, url string) error {
+	if pe.Status.State == state && pe.Status.Message == message && pe.Status.URL == url {
+		return nil
+	}
+	clone := pe.DeepCopy()
+	clone.Status.State = state
+	clone.Status.Message = message
+	clone.Status.URL = url
+
+	peMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(clone)
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicClient.Resource(peGVR).Namespace(clone.GetNamespace()).
+		Update(context.TODO(), &unstructured.Unstructured{Object: peMap}, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("error updating status of pvc export `%s` in `%s` namespace, error: %s",
+			pe.GetName(), pe.GetNamespace(), err)
+	}
+	return nil
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// needsCertificate returns true if the certificate of the daemon or its CA is
+// not in the credentials, or if the certificate is close to its expiry.
+func needsCertificate(data map[string][]byte, now time.Time) bool {
+	if _, ok := data[caKey]; !ok {
+		return true
+	}
+	cert, err := parseCertificate(data[corev1.TLSCertKey])
+	if err != nil {
+		return true
+	}
+	return now.Add(pvcExportCertRenewalPeriod).After(cert.NotAfter)
+}
+
+// generateCertificate adds a certificate and its key for the rsync daemon to
+// the credentials, signed by the CA of the credentials. A new CA is added if
+// there is none or if it expires before the certificate. The clients trust
+// only this CA, so the address is added to the certificate just for the
+// clients which check it.
+func generateCertificate(tc *templateConfig, address string, data map[string][]byte, now time.Time) error {
+	notAfter := now.Add(pvcExportCertValidity)
+	ca, caPrivateKey, err := parseCA(data)
+	if err != nil || ca.NotAfter.Before(notAfter) {
+		ca, caPrivateKey, err = generateCA(tc, data, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := generateSerial()
+	if err != nil {
+		return err
+	}
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: tc.name},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{tc.name, tc.name + "." + tc.sourcePVCNamespace},
+	}
+	if ip := net.ParseIP(address); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else if address != "" {
+		template.DNSNames = append(template.DNSNames, address)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, ca, &key.PublicKey, caPrivateKey)
+	if err != nil {
+		return err
+	}
+	keyPem, err := encodePrivateKey(key)
+	if err != nil {
+		return err
+	}
+	data[corev1.TLSCertKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	data[corev1.TLSPrivateKeyKey] = keyPem
+	return nil
+}
+
+// generateCA adds a new CA and its key to the credentials
+func generateCA(tc *templateConfig, data map[string][]byte, now time.Time) (*x509.Certificate,
+	*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := generateSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: tc.name + "-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(pvcExportCACertValidity),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPem, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	data[caKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	data[caPrivateKeyKey] = keyPem
+	return ca, key, nil
+}
+
+// parseCA returns the CA and its key from the credentials
+func parseCA(data map[string][]byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	ca, err := parseCertificate(data[caKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(data[caPrivateKeyKey])
+	if block == nil {
+		return nil, nil, fmt.Errorf("private key of the CA not found")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ca, key, nil
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("certificate not found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func encodePrivateKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+func generateSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
@@ -0,0 +1,343 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// rebindSteps are the steps of rebinding the source pvc, in order. Every
+// step is done on a sync of its own and recorded in the status, so that
+// rebinding continues from the same step if the operator is restarted.
+var rebindSteps = []string{
+	internalv1alpha1.RebindStepWorkloadsStopped,
+	internalv1alpha1.RebindStepVolumesRetained,
+	internalv1alpha1.RebindStepPVCsDeleted,
+	internalv1alpha1.RebindStepVolumeReleased,
+	internalv1alpha1.RebindStepPVCRecreated,
+	internalv1alpha1.RebindStepPolicyRestored,
+}
+
+// syncRebind recreates the source pvc bound to the populated volume, once
+// the data populator is completed
+func (c *controller) syncRebind(ctx context.Context, dp *internalv1alpha1.DataPopulator) error {
+	status := dp.Status.DeepCopy()
+	if status.Rebind == nil {
+		status.Rebind = &internalv1alpha1.RebindStatus{State: internalv1alpha1.StatusInProgress}
+	}
+	rb := status.Rebind
+	if rb.State == internalv1alpha1.StatusCompleted || rb.State == internalv1alpha1.StatusFailed {
+		return nil
+	}
+	if dp.Spec.SourceCluster != nil || dp.Spec.SourcePVCNamespace != dp.Namespace {
+		return c.failRebind(dp, status, "source pvc can only be rebound when it is in the namespace "+
+			"of the data populator, in the same cluster")
+	}
+
+	tc, err := templateFromDataPopulator(*dp)
+	if err != nil {
+		return fmt.Errorf("error creating template config error: %s", err)
+	}
+	namespace := dp.Namespace
+	sourceName := tc.sourcePVCName
+	populatedName := tc.getDestinationPVCTemplate().Name
+
+	step := rebindSteps[len(rb.Steps)]
+	message := ""
+	switch step {
+	case internalv1alpha1.RebindStepWorkloadsStopped:
+		// The pvcs are deleted, so none of the pods should be using them
+		pods, err := c.getPVCUsers(ctx, namespace, sourceName, populatedName)
+		if err != nil {
+			return err
+		}
+		if len(pods) > 0 {
+			return c.updateRebindStatus(dp, status, "waiting for pods "+strings.Join(pods, ", ")+
+				" using the pvcs to be stopped")
+		}
+		source, err := c.getBoundPVC(ctx, namespace, sourceName)
+		if err != nil {
+			return c.failRebind(dp, status, err.Error())
+		}
+		populated, err := c.getBoundPVC(ctx, namespace, populatedName)
+		if err != nil {
+			return c.failRebind(dp, status, err.Error())
+		}
+		if populated.GetLabels()[createdByLabel] != componentName {
+			return c.failRebind(dp, status, "pvc `"+populatedName+"` found but not created by this operator")
+		}
+		rb.SourcePV = source.Spec.VolumeName
+		rb.PopulatedPV = populated.Spec.VolumeName
+		rb.SourcePVCLabels = source.GetLabels()
+		message = "no pods are using pvc `" + sourceName + "` and pvc `" + populatedName + "`"
+
+	case internalv1alpha1.RebindStepVolumesRetained:
+		// The volumes are kept when their pvcs are deleted
+		policy, err := c.retainVolume(ctx, rb.PopulatedPV)
+		if err != nil {
+			return err
+		}
+		rb.PopulatedPVReclaimPolicy = policy
+		if _, err := c.retainVolume(ctx, rb.SourcePV); err != nil {
+			return err
+		}
+		message = "set reclaim policy of pv `" + rb.SourcePV + "` and pv `" + rb.PopulatedPV + "` to Retain"
+
+	case internalv1alpha1.RebindStepPVCsDeleted:
+		deleted := true
+		for _, name := range []string{populatedName, sourceName} {
+			gone, err := c.deletePVC(ctx, namespace, name)
+			if err != nil {
+				return err
+			}
+			deleted = deleted && gone
+		}
+		if !deleted {
+			// We'll check the pvcs again on the next resync
+			return c.updateRebindStatus(dp, status, "waiting for pvc `"+sourceName+"` and pvc `"+
+				populatedName+"` to be deleted")
+		}
+		message = "deleted pvc `" + sourceName + "` and pvc `" + populatedName + "`"
+
+	case internalv1alpha1.RebindStepVolumeReleased:
+		// The volume is reserved for the pvc which is created with the source name
+		pv, err := c.kubeClient.CoreV1().PersistentVolumes().Get(ctx, rb.PopulatedPV, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error getting pv `%s` error: %s", rb.PopulatedPV, err)
+		}
+		claimRef := &corev1.ObjectReference{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: "v1",
+			Namespace:  namespace,
+			Name:       sourceName,
+		}
+		if !equality.Semantic.DeepEqual(pv.Spec.ClaimRef, claimRef) {
+			pv.Spec.ClaimRef = claimRef
+			if _, err := c.kubeClient.CoreV1().PersistentVolumes().
+				Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("error updating claim of pv `%s` error: %s", pv.Name, err)
+			}
+		}
+		message = "reserved pv `" + rb.PopulatedPV + "` for pvc `" + sourceName + "`"
+
+	case internalv1alpha1.RebindStepPVCRecreated:
+		bound, err := c.ensureReboundPVC(ctx, dp, rb, sourceName)
+		if err != nil {
+			return err
+		}
+		if !bound {
+			// We'll check the pvc again on the next resync
+			return c.updateRebindStatus(dp, status, "waiting for pvc `"+sourceName+"` to be bound to pv `"+
+				rb.PopulatedPV+"`")
+		}
+		message = "pvc `" + sourceName + "` is bound to pv `" + rb.PopulatedPV + "`"
+
+	case internalv1alpha1.RebindStepPolicyRestored:
+		policy, err := c.restoreReclaimPolicy(ctx, rb.PopulatedPV, rb.PopulatedPVReclaimPolicy)
+		if err != nil {
+			return err
+		}
+		rb.PopulatedPVReclaimPolicy = policy
+		message = fmt.Sprintf("set reclaim policy of pv `%s` to %s", rb.PopulatedPV, policy)
+	}
+
+	rb.Steps = append(rb.Steps, internalv1alpha1.RebindStep{
+		Name:    step,
+		Time:    metav1.Now(),
+		Message: message,
+	})
+	if len(rb.Steps) == len(rebindSteps) {
+		rb.State = internalv1alpha1.StatusCompleted
+		message = "pvc `" + sourceName + "` is bound to the populated pv `" + rb.PopulatedPV +
+			"`, pv `" + rb.SourcePV + "` of the source pvc is retained"
+	}
+	return c.updateRebindStatus(dp, status, message)
+}
+
+// getPVCUsers returns the names of the pods, which are not finished, using any of the pvcs
+func (c *controller) getPVCUsers(ctx context.Context, namespace string, pvcNames ...string) ([]string, error) {
+	pods, err := c.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods in `%s` namespace error: %s", namespace, err)
+	}
+	users := []string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && containsString(pvcNames, vol.PersistentVolumeClaim.ClaimName) {
+				users = append(users, "`"+pod.Name+"`")
+				break
+			}
+		}
+	}
+	return users, nil
+}
+
+// getBoundPVC returns the pvc if it is bound to a volume
+func (c *controller) getBoundPVC(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	pvc, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s", name, namespace, err)
+	}
+	if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return nil, fmt.Errorf("pvc `%s` is not bound to a pv", name)
+	}
+	return pvc, nil
+}
+
+// retainVolume sets the reclaim policy of the pv to Retain and returns its
+// original policy. The original policy is kept in an annotation of the pv,
+// set along with the policy, so that it is not lost if the status of the
+// data populator is not updated.
+func (c *controller) retainVolume(ctx context.Context, name string) (corev1.PersistentVolumeReclaimPolicy, error) {
+	pv, err := c.kubeClient.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting pv `%s` error: %s", name, err)
+	}
+	if policy, ok := pv.GetAnnotations()[reclaimPolicyAnnotation]; ok {
+		return corev1.PersistentVolumeReclaimPolicy(policy), nil
+	}
+	original := pv.Spec.PersistentVolumeReclaimPolicy
+	if pv.Annotations == nil {
+		pv.Annotations = map[string]string{}
+	}
+	pv.Annotations[reclaimPolicyAnnotation] = string(original)
+	pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	if _, err := c.kubeClient.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("error setting reclaim policy of pv `%s` to %s error: %s",
+			name, corev1.PersistentVolumeReclaimRetain, err)
+	}
+	return original, nil
+}
+
+// restoreReclaimPolicy sets the reclaim policy of the pv back to its original
+// policy from its annotation, or to the given policy if the annotation is
+// already removed, and returns the policy
+func (c *controller) restoreReclaimPolicy(ctx context.Context, name string,
+	policy corev1.PersistentVolumeReclaimPolicy) (corev1.PersistentVolumeReclaimPolicy, error) {
+	pv, err := c.kubeClient.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting pv `%s` error: %s", name, err)
+	}
+	original, ok := pv.GetAnnotations()[reclaimPolicyAnnotation]
+	if !ok {
+		return policy, nil
+	}
+	policy = corev1.PersistentVolumeReclaimPolicy(original)
+	delete(pv.Annotations, reclaimPolicyAnnotation)
+	pv.Spec.PersistentVolumeReclaimPolicy = policy
+	if _, err := c.kubeClient.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("error setting reclaim policy of pv `%s` to %s error: %s", name, policy, err)
+	}
+	return policy, nil
+}
+
+// deletePVC deletes the pvc and returns true once it is gone
+func (c *controller) deletePVC(ctx context.Context, namespace, name string) (bool, error) {
+	pvc, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s", name, namespace, err)
+	}
+	if pvc.GetDeletionTimestamp() != nil {
+		return false, nil
+	}
+	err = c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("error deleting pvc `%s` in `%s` namespace error: %s", name, namespace, err)
+	}
+	return false, nil
+}
+
+// ensureReboundPVC creates the pvc with the source name bound to the
+// populated volume, and returns true once it is bound
+func (c *controller) ensureReboundPVC(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	rb *internalv1alpha1.RebindStatus, name string) (bool, error) {
+	pvc, err := c.kubeClient.CoreV1().PersistentVolumeClaims(dp.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		if pvc.Spec.VolumeName != rb.PopulatedPV {
+			return false, fmt.Errorf("pvc `%s` found but not bound to pv `%s`", name, rb.PopulatedPV)
+		}
+		return pvc.Status.Phase == corev1.ClaimBound, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s", name, dp.Namespace, err)
+	}
+
+	pv, err := c.kubeClient.CoreV1().PersistentVolumes().Get(ctx, rb.PopulatedPV, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("error getting pv `%s` error: %s", rb.PopulatedPV, err)
+	}
+	// The storage class and size must match the volume for the pvc to be bound to it
+	spec := *dp.Spec.DestinationPVC.DeepCopy()
+	spec.DataSource = nil
+	spec.DataSourceRef = nil
+	spec.Selector = nil
+	spec.VolumeName = pv.Name
+	spec.StorageClassName = &pv.Spec.StorageClassName
+	if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+		spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: capacity}
+	}
+	pvc = &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: rb.SourcePVCLabels,
+		},
+		Spec: spec,
+	}
+	if _, err := c.kubeClient.CoreV1().PersistentVolumeClaims(dp.Namespace).
+		Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+		return false, fmt.Errorf("error creating pvc `%s` in `%s` namespace error: %s", name, dp.Namespace, err)
+	}
+	return false, nil
+}
+
+// failRebind marks rebinding as failed, the data populator stays completed
+func (c *controller) failRebind(dp *internalv1alpha1.DataPopulator, status *internalv1alpha1.DataPopulatorStatus,
+	message string) error {
+	status.Rebind.State = internalv1alpha1.StatusFailed
+	return c.updateRebindStatus(dp, status, message)
+}
+
+// updateRebindStatus updates the rebind status of the data populator, if it is changed
+func (c *controller) updateRebindStatus(dp *internalv1alpha1.DataPopulator, status *internalv1alpha1.DataPopulatorStatus,
+	message string) error {
+	clone := dp.DeepCopy()
+	clone.Status = *status.DeepCopy()
+	clone.Status.Rebind.Message = message
+	if equality.Semantic.DeepEqual(clone.Status, dp.Status) {
+		return nil
+	}
+	if err := c.updateDataPopulator(clone); err != nil {
+		return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
+			dp.GetName(), dp.GetNamespace(), err)
+	}
+	return nil
+}
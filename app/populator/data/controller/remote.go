@@ -0,0 +1,237 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// getSourceClusterController returns a controller whose kube client talks to
+// the cluster of the source pvc of the data populator.
+func (c *controller) getSourceClusterController(ctx context.Context,
+	dp *internalv1alpha1.DataPopulator) (*controller, error) {
+	name := dp.Spec.SourceCluster.KubeconfigSecret
+	secret, err := c.kubeClient.CoreV1().Secrets(dp.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting kubeconfig secret `%s` in `%s` namespace error: %s",
+			name, dp.Namespace, err)
+	}
+	kubeconfig, ok := secret.Data[kubeconfigKey]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret `%s` in `%s` namespace has no `%s` key",
+			name, dp.Namespace, kubeconfigKey)
+	}
+	cfg, err := getRESTConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing kubeconfig secret `%s` in `%s` namespace error: %s",
+			name, dp.Namespace, err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client of the source cluster error: %s", err)
+	}
+
+	remote := *c
+	remote.kubeClient = kubeClient
+	return &remote, nil
+}
+
+// getRESTConfig returns the config of the current context of the kubeconfig.
+// The kubeconfig is written by the users of the namespace, so only the
+// inline credentials are used. The exec plugins, the auth providers and the
+// paths of the files are rejected as they run programs or read the files of
+// the operator.
+func getRESTConfig(kubeconfig []byte) (*rest.Config, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("current context `%s` is not found", config.CurrentContext)
+	}
+	cluster, ok := config.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("cluster `%s` is not found", kubeContext.Cluster)
+	}
+	authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("user `%s` is not found", kubeContext.AuthInfo)
+	}
+
+	if cluster.Server == "" {
+		return nil, fmt.Errorf("server of cluster `%s` is not set", kubeContext.Cluster)
+	}
+	if cluster.CertificateAuthority != "" {
+		return nil, fmt.Errorf("certificate-authority of cluster `%s` is not supported, "+
+			"certificate-authority-data must be used", kubeContext.Cluster)
+	}
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"exec", authInfo.Exec != nil},
+		{"auth-provider", authInfo.AuthProvider != nil},
+		{"tokenFile", authInfo.TokenFile != ""},
+		{"client-certificate", authInfo.ClientCertificate != ""},
+		{"client-key", authInfo.ClientKey != ""},
+		{"username", authInfo.Username != ""},
+		{"password", authInfo.Password != ""},
+		{"as", authInfo.Impersonate != ""},
+		{"as-groups", len(authInfo.ImpersonateGroups) > 0},
+		{"as-user-extra", len(authInfo.ImpersonateUserExtra) > 0},
+	} {
+		if field.set {
+			return nil, fmt.Errorf("%s of user `%s` is not supported, only token, "+
+				"client-certificate-data and client-key-data can be used", field.name, kubeContext.AuthInfo)
+		}
+	}
+
+	return &rest.Config{
+		Host:        cluster.Server,
+		BearerToken: authInfo.Token,
+		TLSClientConfig: rest.TLSClientConfig{
+			ServerName: cluster.TLSServerName,
+			CAData:     cluster.CertificateAuthorityData,
+			CertData:   authInfo.ClientCertificateData,
+			KeyData:    authInfo.ClientKeyData,
+		},
+	}, nil
+}
+
+// ensureRemoteRsyncDaemon creates the rsync daemon in the source cluster and
+// writes its connection details to a secret in this cluster. It returns false
+// if the address of the daemon is not yet known.
+func (c *controller) ensureRemoteRsyncDaemon(ctx context.Context, remote *controller,
+	dp *internalv1alpha1.DataPopulator, tc *templateConfig) (bool, error) {
+	address := dp.Spec.SourceCluster.Address
+	credentials, err := remote.ensureCredentialsSecret(ctx, tc, address)
+	if err != nil {
+		return false, fmt.Errorf("error ensuring credentials secret `%s` in `%s` namespace of the source cluster, error: %s",
+			tc.credentialsSecret, tc.sourcePVCNamespace, err)
+	}
+
+	if err := remote.ensureRsyncDaemon(true, tc, tc.sourcePVCNamespace); err != nil {
+		return false, err
+	}
+
+	url, err := remote.getPVCExportURL(ctx, tc, address)
+	if err != nil || url == "" {
+		return false, err
+	}
+
+	connection := map[string][]byte{
+		urlKey:      []byte(url),
+		pathKey:     []byte(SourcePvcMountPath),
+		usernameKey: []byte(tc.rsyncUsername),
+		passwordKey: credentials[passwordKey],
+		caKey:       credentials[caKey],
+	}
+	if err := c.ensureConnectionSecret(ctx, dp.Namespace, tc.connectionSecret, nil, connection); err != nil {
+		return false, fmt.Errorf("error ensuring connection secret `%s` in `%s` namespace, error: %s",
+			tc.connectionSecret, dp.Namespace, err)
+	}
+	return true, nil
+}
+
+// deleteRemoteRsyncDaemon deletes the rsync daemon resources from the source
+// cluster and the connection secret from this cluster.
+func (c *controller) deleteRemoteRsyncDaemon(ctx context.Context, dp *internalv1alpha1.DataPopulator,
+	tc *templateConfig) error {
+	// The kubeconfig secret can be deleted first along with the namespace,
+	// the resources of the source cluster can't be deleted then.
+	name := dp.Spec.SourceCluster.KubeconfigSecret
+	_, err := c.kubeClient.CoreV1().Secrets(dp.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting kubeconfig secret `%s` in `%s` namespace error: %s",
+			name, dp.Namespace, err)
+	}
+	if errors.IsNotFound(err) {
+		klog.Warningf("kubeconfig secret `%s` in `%s` namespace not found, rsync daemon `%s` "+
+			"in the source cluster is not deleted", name, dp.Namespace, tc.name)
+	} else {
+		remote, err := c.getSourceClusterController(ctx, dp)
+		if err != nil {
+			return err
+		}
+		if err := remote.ensureRsyncDaemon(false, tc, tc.sourcePVCNamespace); err != nil {
+			return err
+		}
+		credentials := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tc.credentialsSecret}}
+		if err := remote.ensureSecret(false, tc.sourcePVCNamespace, &credentials); err != nil {
+			return fmt.Errorf("error ensuring(false) secret `%s` in `%s` namespace of the source cluster, error: %s",
+				credentials.Name, tc.sourcePVCNamespace, err)
+		}
+	}
+
+	connection := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tc.connectionSecret}}
+	if err := c.ensureSecret(false, dp.Namespace, &connection); err != nil {
+		return fmt.Errorf("error ensuring(false) secret `%s` in `%s` namespace, error: %s",
+			connection.Name, dp.Namespace, err)
+	}
+	return nil
+}
+
+// ensureDataPopulatorFinalizer adds or removes the finalizer which keeps the
+// data populator till the resources in the source cluster are deleted.
+func (c *controller) ensureDataPopulatorFinalizer(dp *internalv1alpha1.DataPopulator, want bool) error {
+	finalizers := removeString(dp.GetFinalizers(), dataPopulatorFinalizer)
+	if want == containsString(dp.GetFinalizers(), dataPopulatorFinalizer) {
+		return nil
+	}
+	if want {
+		finalizers = append(finalizers, dataPopulatorFinalizer)
+	}
+
+	clone := dp.DeepCopy()
+	clone.SetFinalizers(finalizers)
+	if err := c.updateDataPopulator(clone); err != nil {
+		return fmt.Errorf("error updating finalizers of data populator `%s` in `%s` namespace, error: %s",
+			dp.GetName(), dp.GetNamespace(), err)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	result := []string{}
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestGetRESTConfig(t *testing.T) {
+	kubeconfig := func(cluster, user string) []byte {
+		return []byte(`apiVersion: v1
+kind: Config
+current-context: source
+contexts:
+- name: source
+  context:
+    cluster: source
+    user: source
+clusters:
+- name: source
+  cluster:
+    server: https://source.example.com:6443
+` + cluster + `
+users:
+- name: source
+  user:
+` + user)
+	}
+	tests := map[string]struct {
+		kubeconfig []byte
+		want       *rest.Config
+		wantErr    bool
+	}{
+		"token": {
+			kubeconfig: kubeconfig("    certificate-authority-data: Y2E=", "    token: secret"),
+			want: &rest.Config{
+				Host:            "https://source.example.com:6443",
+				BearerToken:     "secret",
+				TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")},
+			},
+		},
+		"client certificate": {
+			kubeconfig: kubeconfig("    certificate-authority-data: Y2E=",
+				"    client-certificate-data: Y2VydA==\n    client-key-data: a2V5"),
+			want: &rest.Config{
+				Host: "https://source.example.com:6443",
+				TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca"),
+					CertData: []byte("cert"), KeyData: []byte("key")},
+			},
+		},
+		"exec plugin": {
+			kubeconfig: kubeconfig("", "    exec:\n      apiVersion: client.authentication.k8s.io/v1beta1\n      command: sh"),
+			wantErr:    true,
+		},
+		"auth provider": {
+			kubeconfig: kubeconfig("", "    auth-provider:\n      name: gcp"),
+			wantErr:    true,
+		},
+		"token file": {
+			kubeconfig: kubeconfig("", "    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token"),
+			wantErr:    true,
+		},
+		"client certificate file": {
+			kubeconfig: kubeconfig("", "    client-certificate: /etc/tls.crt\n    client-key-data: a2V5"),
+			wantErr:    true,
+		},
+		"client key file": {
+			kubeconfig: kubeconfig("", "    client-certificate-data: Y2VydA==\n    client-key: /etc/tls.key"),
+			wantErr:    true,
+		},
+		"certificate authority file": {
+			kubeconfig: kubeconfig("    certificate-authority: /etc/ca.crt", "    token: secret"),
+			wantErr:    true,
+		},
+		"impersonation": {
+			kubeconfig: kubeconfig("", "    token: secret\n    as: admin"),
+			wantErr:    true,
+		},
+		"missing context": {
+			kubeconfig: []byte("apiVersion: v1\nkind: Config\ncurrent-context: source\n"),
+			wantErr:    true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := getRESTConfig(test.kubeconfig)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("getRESTConfig() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getRESTConfig() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
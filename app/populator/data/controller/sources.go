@@ -0,0 +1,100 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// sourceContainerPrefix is the prefix of the names of the init containers of
+// the populator pod, which copy the sources one after the other. They are
+// followed by the index of the source.
+const sourceContainerPrefix = "source-"
+
+// getSourcesStatus returns the progress of copying each of the sources which
+// are merged into the destination pvc, from the init containers of the
+// populator pod. A source which is copied stays Completed when the populator
+// pod is created again.
+func (c *controller) getSourcesStatus(ctx context.Context, dp *internalv1alpha1.DataPopulator, dptc *templateConfig,
+	destinationPVC *corev1.PersistentVolumeClaim) ([]internalv1alpha1.DataPopulatorSourceStatus, error) {
+	if len(dptc.sources) == 0 {
+		return nil, nil
+	}
+	statuses := make([]internalv1alpha1.DataPopulatorSourceStatus, len(dptc.sources))
+	for i, stc := range dptc.sources {
+		statuses[i] = internalv1alpha1.DataPopulatorSourceStatus{
+			PVC:       stc.sourcePVCName,
+			Namespace: stc.sourcePVCNamespace,
+			State:     internalv1alpha1.StatusPending,
+		}
+		for _, status := range dp.Status.Sources {
+			if status.PVC == stc.sourcePVCName && status.Namespace == stc.sourcePVCNamespace &&
+				status.State == internalv1alpha1.StatusCompleted {
+				statuses[i] = *status.DeepCopy()
+			}
+		}
+	}
+
+	podName := populatorPodPrefix + string(destinationPVC.UID)
+	pod, err := c.kubeClient.CoreV1().Pods(RsyncPopulatorNamespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return statuses, nil
+		}
+		return nil, fmt.Errorf("error getting pod `%s` in `%s` namespace error: %s",
+			podName, RsyncPopulatorNamespace, err)
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if !strings.HasPrefix(cs.Name, sourceContainerPrefix) {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(cs.Name, sourceContainerPrefix))
+		if err != nil || i < 0 || i >= len(statuses) || statuses[i].State == internalv1alpha1.StatusCompleted {
+			continue
+		}
+		switch {
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+			finishedAt := cs.State.Terminated.FinishedAt
+			statuses[i].State = internalv1alpha1.StatusCompleted
+			statuses[i].CompletionTime = &finishedAt
+		case cs.State.Running != nil || cs.State.Terminated != nil:
+			// A source which failed is copied again by the next populator pod
+			statuses[i].State = internalv1alpha1.StatusInProgress
+		}
+	}
+	return statuses, nil
+}
+
+// getSourcesMessage returns how many of the sources are copied
+func getSourcesMessage(statuses []internalv1alpha1.DataPopulatorSourceStatus) string {
+	completed := 0
+	for _, status := range statuses {
+		if status.State == internalv1alpha1.StatusCompleted {
+			completed++
+		}
+	}
+	return fmt.Sprintf("copied %d of %d sources", completed, len(statuses))
+}
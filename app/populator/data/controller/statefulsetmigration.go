@@ -0,0 +1,472 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func (c *controller) handleStatefulSetMigration(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.migrationQueue.Add(key)
+}
+
+func (c *controller) runStatefulSetMigrationWorker() {
+	c.runQueueWorker(c.migrationQueue, c.syncStatefulSetMigration)
+}
+
+// syncStatefulSetMigration scales down the statefulset, migrates each of its
+// volumes with a data populator which rebinds the pvc to the populated volume,
+// and then recreates the statefulset using the new storage class
+func (c *controller) syncStatefulSetMigration(ctx context.Context, key, namespace, name string) error {
+	unstruct, err := c.smLister.Namespace(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The data populators and the configmap are garbage collected with the migration
+			utilruntime.HandleError(fmt.Errorf("statefulset migration '%s' in work queue no longer exists", key))
+			return nil
+		}
+		return fmt.Errorf("error getting statefulset migration error: %s", err)
+	}
+
+	migration := internalv1alpha1.StatefulSetMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+		&migration); err != nil {
+		return fmt.Errorf("error converting statefulset migration `%s` in `%s` namespace error: %s",
+			unstruct.GetName(), unstruct.GetNamespace(), err)
+	}
+
+	// If the status is completed or failed then don't perform any action
+	if migration.Status.State == internalv1alpha1.StatusCompleted ||
+		migration.Status.State == internalv1alpha1.StatusFailed {
+		return nil
+	}
+
+	switch migration.Status.Phase {
+	case internalv1alpha1.StatefulSetPhaseScalingDown:
+		return c.scaleDownStatefulSet(ctx, &migration)
+	case internalv1alpha1.StatefulSetPhaseMigrating:
+		return c.migrateStatefulSetVolumes(ctx, &migration)
+	case internalv1alpha1.StatefulSetPhaseRecreating:
+		return c.recreateStatefulSet(ctx, &migration)
+	}
+	return c.startStatefulSetMigration(ctx, &migration)
+}
+
+// startStatefulSetMigration finds the pvcs of all the replicas of the
+// statefulset, and keeps the statefulset in a configmap
+func (c *controller) startStatefulSetMigration(ctx context.Context, sm *internalv1alpha1.StatefulSetMigration) error {
+	status := sm.Status.DeepCopy()
+	if sm.Spec.StatefulSet == "" || sm.Spec.StorageClassName == "" {
+		return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusFailed,
+			"statefulSet and storageClassName must be set", status)
+	}
+
+	sts, err := c.kubeClient.AppsV1().StatefulSets(sm.Namespace).Get(ctx, sm.Spec.StatefulSet, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting statefulset `%s` in `%s` namespace error: %s",
+			sm.Spec.StatefulSet, sm.Namespace, err)
+	}
+	if _, err := c.kubeClient.StorageV1().StorageClasses().
+		Get(ctx, sm.Spec.StorageClassName, metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("error getting storage class `%s` error: %s", sm.Spec.StorageClassName, err)
+	}
+
+	templates := []string{}
+	for _, tmpl := range sts.Spec.VolumeClaimTemplates {
+		if len(sm.Spec.VolumeClaimTemplates) == 0 || containsString(sm.Spec.VolumeClaimTemplates, tmpl.Name) {
+			templates = append(templates, tmpl.Name)
+		}
+	}
+	for _, name := range sm.Spec.VolumeClaimTemplates {
+		if !containsString(templates, name) {
+			return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusFailed,
+				"statefulset `"+sts.Name+"` has no volume claim template `"+name+"`", status)
+		}
+	}
+	if len(templates) == 0 {
+		return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusFailed,
+			"statefulset `"+sts.Name+"` has no volume claim templates", status)
+	}
+
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	ordinals := replicas
+	if replicas == 0 {
+		// The statefulset is already scaled down, its pvcs are found by their names
+		ordinals, err = c.getStatefulSetPVCOrdinals(ctx, sm.Namespace, sts.Name, templates)
+		if err != nil {
+			return err
+		}
+		if ordinals == 0 {
+			return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusFailed,
+				"statefulset `"+sts.Name+"` is scaled down to zero and has no pvcs", status)
+		}
+	}
+	volumes := []internalv1alpha1.StatefulSetMigrationVolume{}
+	for ordinal := int32(0); ordinal < ordinals; ordinal++ {
+		for _, tmpl := range templates {
+			pvcName := tmpl + "-" + sts.Name + "-" + strconv.Itoa(int(ordinal))
+			if _, err := c.getBoundPVC(ctx, sm.Namespace, pvcName); err != nil {
+				return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusFailed, err.Error(), status)
+			}
+			// The populated volume is created on the node of the replica, if its
+			// storage class waits for a consumer
+			node, err := c.getPVCConsumerNode(ctx, sm.Namespace, pvcName, "")
+			if err != nil {
+				return err
+			}
+			volumes = append(volumes, internalv1alpha1.StatefulSetMigrationVolume{
+				PVC:                 pvcName,
+				VolumeClaimTemplate: tmpl,
+				Ordinal:             ordinal,
+				Node:                node,
+				DataPopulator:       sm.Name + "-" + pvcName,
+			})
+		}
+	}
+
+	data, err := json.Marshal(sts)
+	if err != nil {
+		return fmt.Errorf("error encoding statefulset `%s` error: %s", sts.Name, err)
+	}
+	cmTemplate := getStatefulSetCmTemplate(*sm, string(data))
+	if err := c.ensureConfigMap(true, sm.Namespace, &cmTemplate); err != nil {
+		return fmt.Errorf("error ensuring(true) configmap `%s` in `%s` namespace, error: %s",
+			cmTemplate.GetName(), sm.Namespace, err)
+	}
+
+	now := metav1.Now()
+	status.Phase = internalv1alpha1.StatefulSetPhaseScalingDown
+	status.Replicas = replicas
+	status.TotalVolumes = int32(len(volumes))
+	status.Volumes = volumes
+	status.StartTime = &now
+	return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusInProgress,
+		"scaling down statefulset `"+sts.Name+"`", status)
+}
+
+// scaleDownStatefulSet scales down the statefulset and waits till none of
+// its pods are using the pvcs
+func (c *controller) scaleDownStatefulSet(ctx context.Context, sm *internalv1alpha1.StatefulSetMigration) error {
+	status := sm.Status.DeepCopy()
+	scale, err := c.getScale(ctx, sm.Namespace, statefulSetKind, sm.Spec.StatefulSet)
+	if err != nil {
+		return err
+	}
+	if scale.Spec.Replicas != 0 {
+		scale.Spec.Replicas = 0
+		return c.updateScale(ctx, sm.Namespace, statefulSetKind, scale)
+	}
+
+	pvcs := []string{}
+	for _, vol := range status.Volumes {
+		pvcs = append(pvcs, vol.PVC)
+	}
+	pods, err := c.getPVCUsers(ctx, sm.Namespace, pvcs...)
+	if err != nil {
+		return err
+	}
+	if len(pods) > 0 {
+		return c.updateStatefulSetMigrationStatus(sm, sm.Status.State,
+			"waiting for pods "+strings.Join(pods, ", ")+" to be stopped", status)
+	}
+
+	status.Phase = internalv1alpha1.StatefulSetPhaseMigrating
+	return c.updateStatefulSetMigrationStatus(sm, sm.Status.State, getStatefulSetMigrationProgress(status), status)
+}
+
+// migrateStatefulSetVolumes creates the data populators of the volumes and
+// tracks them till all the pvcs are rebound to the populated volumes
+func (c *controller) migrateStatefulSetVolumes(ctx context.Context, sm *internalv1alpha1.StatefulSetMigration) error {
+	status := sm.Status.DeepCopy()
+	migrated := int32(0)
+	failed := []string{}
+	for i := range status.Volumes {
+		vol := &status.Volumes[i]
+		unstruct, err := c.dpLister.Namespace(sm.Namespace).Get(vol.DataPopulator)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("error getting data populator `%s` in `%s` namespace error: %s",
+					vol.DataPopulator, sm.Namespace, err)
+			}
+			if err := c.createMigrationDataPopulator(ctx, sm, *vol); err != nil {
+				return err
+			}
+			continue
+		}
+		dp := internalv1alpha1.DataPopulator{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstruct.UnstructuredContent(),
+			&dp); err != nil {
+			return fmt.Errorf("error converting data populator `%s` in `%s` namespace error: %s",
+				unstruct.GetName(), unstruct.GetNamespace(), err)
+		}
+
+		vol.State = dp.Status.State
+		vol.RebindState = ""
+		if dp.Status.Rebind != nil {
+			vol.RebindState = dp.Status.Rebind.State
+		}
+		if vol.State == internalv1alpha1.StatusWaitingForConsumer && vol.Node != "" {
+			if err := c.setSelectedNode(ctx, sm.Namespace, vol.PVC+"-populated", vol.Node); err != nil {
+				return err
+			}
+		}
+		if vol.State == internalv1alpha1.StatusFailed || vol.RebindState == internalv1alpha1.StatusFailed {
+			failed = append(failed, "`"+vol.DataPopulator+"`")
+		}
+		if vol.RebindState == internalv1alpha1.StatusCompleted {
+			migrated++
+		}
+	}
+	status.MigratedVolumes = migrated
+
+	if len(failed) > 0 {
+		message := "data populators " + strings.Join(failed, ", ") + " failed"
+		if migrated+int32(len(failed)) < status.TotalVolumes {
+			// The other volumes can't be used by the statefulset while they
+			// are being populated or rebound
+			return c.updateStatefulSetMigrationStatus(sm, sm.Status.State,
+				message+", waiting for the other volumes before rolling back", status)
+		}
+		rollback, err := c.rollBackStatefulSetMigration(ctx, sm)
+		if err != nil {
+			return err
+		}
+		return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusFailed,
+			message+", "+rollback, status)
+	}
+	if migrated == status.TotalVolumes {
+		status.Phase = internalv1alpha1.StatefulSetPhaseRecreating
+		return c.updateStatefulSetMigrationStatus(sm, sm.Status.State,
+			"recreating statefulset `"+sm.Spec.StatefulSet+"`", status)
+	}
+	return c.updateStatefulSetMigrationStatus(sm, sm.Status.State, getStatefulSetMigrationProgress(status), status)
+}
+
+// rollBackStatefulSetMigration scales the statefulset back to its replicas and
+// deletes the configmap keeping it, and returns the message of the rollback.
+// The pvcs which are rebound keep using the volumes of the new storage class,
+// the others their volumes of the old one. If a pvc is not bound, like when
+// rebinding it failed, the statefulset is kept scaled down, as its replica
+// would otherwise be started with a new empty volume.
+func (c *controller) rollBackStatefulSetMigration(ctx context.Context,
+	sm *internalv1alpha1.StatefulSetMigration) (string, error) {
+	for _, vol := range sm.Status.Volumes {
+		if _, err := c.getBoundPVC(ctx, sm.Namespace, vol.PVC); err != nil {
+			return "statefulset `" + sm.Spec.StatefulSet + "` is kept scaled down as pvc `" +
+				vol.PVC + "` is not bound, it is kept in configmap `" +
+				statefulSetMigrationNamePrefix + sm.Name + "`", nil
+		}
+	}
+	scale, err := c.getScale(ctx, sm.Namespace, statefulSetKind, sm.Spec.StatefulSet)
+	if err != nil {
+		return "", err
+	}
+	if scale.Spec.Replicas != sm.Status.Replicas {
+		scale.Spec.Replicas = sm.Status.Replicas
+		if err := c.updateScale(ctx, sm.Namespace, statefulSetKind, scale); err != nil {
+			return "", err
+		}
+	}
+	cm := getStatefulSetCmTemplate(*sm, "")
+	if err := c.ensureConfigMap(false, sm.Namespace, &cm); err != nil {
+		return "", fmt.Errorf("error ensuring(false) configmap `%s` in `%s` namespace, error: %s",
+			cm.GetName(), sm.Namespace, err)
+	}
+	return fmt.Sprintf("statefulset `%s` is scaled back to %d replicas", sm.Spec.StatefulSet,
+		sm.Status.Replicas), nil
+}
+
+// getStatefulSetPVCOrdinals returns the number of ordinals of the statefulset
+// having pvcs, found by the `<template>-<statefulset>-<ordinal>` names of the
+// pvcs of its volume claim templates
+func (c *controller) getStatefulSetPVCOrdinals(ctx context.Context, namespace, name string,
+	templates []string) (int32, error) {
+	pvcs, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("error listing pvcs in `%s` namespace error: %s", namespace, err)
+	}
+	ordinals := int32(0)
+	for _, pvc := range pvcs.Items {
+		for _, tmpl := range templates {
+			prefix := tmpl + "-" + name + "-"
+			if !strings.HasPrefix(pvc.Name, prefix) {
+				continue
+			}
+			suffix := strings.TrimPrefix(pvc.Name, prefix)
+			ordinal, err := strconv.ParseInt(suffix, 10, 32)
+			if err != nil || ordinal < 0 || strconv.FormatInt(ordinal, 10) != suffix {
+				continue
+			}
+			if int32(ordinal) >= ordinals {
+				ordinals = int32(ordinal) + 1
+			}
+		}
+	}
+	return ordinals, nil
+}
+
+// createMigrationDataPopulator creates the data populator of a volume, the
+// destination pvc is requested like the pvc of the replica
+func (c *controller) createMigrationDataPopulator(ctx context.Context, sm *internalv1alpha1.StatefulSetMigration,
+	vol internalv1alpha1.StatefulSetMigrationVolume) error {
+	pvc, err := c.kubeClient.CoreV1().PersistentVolumeClaims(sm.Namespace).Get(ctx, vol.PVC, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s", vol.PVC, sm.Namespace, err)
+	}
+	dp := getMigrationDataPopulatorTemplate(*sm, vol, pvc)
+	dpMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dp)
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicClient.Resource(dpGVR).Namespace(sm.Namespace).
+		Create(ctx, &unstructured.Unstructured{Object: dpMap}, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating data populator `%s` in `%s` namespace error: %s",
+			dp.Name, sm.Namespace, err)
+	}
+	return nil
+}
+
+// setSelectedNode sets the node of the pvc, so that its volume is provisioned on it
+func (c *controller) setSelectedNode(ctx context.Context, namespace, name, node string) error {
+	pvc, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The data populator creates the pvc on its next sync
+			return nil
+		}
+		return fmt.Errorf("error getting pvc `%s` in `%s` namespace error: %s", name, namespace, err)
+	}
+	if pvc.GetAnnotations()[nodeNameAnnotation] != "" {
+		return nil
+	}
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	pvc.Annotations[nodeNameAnnotation] = node
+	if _, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).
+		Update(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error setting node of pvc `%s` in `%s` namespace error: %s", name, namespace, err)
+	}
+	return nil
+}
+
+// recreateStatefulSet deletes the statefulset leaving its pvcs, like
+// --cascade=orphan, and creates it again using the new storage class
+func (c *controller) recreateStatefulSet(ctx context.Context, sm *internalv1alpha1.StatefulSetMigration) error {
+	status := sm.Status.DeepCopy()
+	cmName := statefulSetMigrationNamePrefix + sm.Name
+	cm, err := c.kubeClient.CoreV1().ConfigMaps(sm.Namespace).Get(ctx, cmName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting configmap `%s` in `%s` namespace error: %s", cmName, sm.Namespace, err)
+	}
+	original := &appsv1.StatefulSet{}
+	if err := json.Unmarshal([]byte(cm.Data[statefulSetKey]), original); err != nil {
+		return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusFailed,
+			"statefulset kept in configmap `"+cmName+"` can't be decoded: "+err.Error(), status)
+	}
+
+	sts, err := c.kubeClient.AppsV1().StatefulSets(sm.Namespace).Get(ctx, sm.Spec.StatefulSet, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting statefulset `%s` in `%s` namespace error: %s",
+			sm.Spec.StatefulSet, sm.Namespace, err)
+	}
+	if err != nil {
+		stsTemplate := getRecreatedStatefulSetTemplate(*sm, original)
+		if _, err := c.kubeClient.AppsV1().StatefulSets(sm.Namespace).
+			Create(ctx, &stsTemplate, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating statefulset `%s` in `%s` namespace error: %s",
+				stsTemplate.Name, sm.Namespace, err)
+		}
+		return nil
+	}
+	if sts.UID == original.UID {
+		if sts.GetDeletionTimestamp() == nil {
+			orphan := metav1.DeletePropagationOrphan
+			if err := c.kubeClient.AppsV1().StatefulSets(sm.Namespace).Delete(ctx, sts.Name,
+				metav1.DeleteOptions{PropagationPolicy: &orphan}); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("error deleting statefulset `%s` in `%s` namespace error: %s",
+					sts.Name, sm.Namespace, err)
+			}
+		}
+		// We'll check the statefulset again on the next resync
+		return nil
+	}
+
+	// The statefulset is recreated, the configmap is not needed anymore
+	if err := c.ensureConfigMap(false, sm.Namespace, cm); err != nil {
+		return fmt.Errorf("error ensuring(false) configmap `%s` in `%s` namespace, error: %s",
+			cmName, sm.Namespace, err)
+	}
+	now := metav1.Now()
+	status.Phase = ""
+	status.CompletionTime = &now
+	return c.updateStatefulSetMigrationStatus(sm, internalv1alpha1.StatusCompleted,
+		"recreated statefulset `"+sts.Name+"` using storage class `"+sm.Spec.StorageClassName+"`", status)
+}
+
+// getStatefulSetMigrationProgress returns the message of the progress of the migration
+func getStatefulSetMigrationProgress(status *internalv1alpha1.StatefulSetMigrationStatus) string {
+	return fmt.Sprintf("migrated %d of %d volumes", status.MigratedVolumes, status.TotalVolumes)
+}
+
+// updateStatefulSetMigrationStatus updates the status of the statefulset migration, if it is changed
+func (c *controller) updateStatefulSetMigrationStatus(sm *internalv1alpha1.StatefulSetMigration, state, message string,
+	status *internalv1alpha1.StatefulSetMigrationStatus) error {
+	clone := sm.DeepCopy()
+	clone.Status = *status.DeepCopy()
+	clone.Status.State = state
+	clone.Status.Message = message
+	if equality.Semantic.DeepEqual(clone.Status, sm.Status) {
+		return nil
+	}
+
+	smMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(clone)
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicClient.Resource(smGVR).Namespace(clone.GetNamespace()).
+		Update(context.TODO(), &unstructured.Unstructured{Object: smMap}, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("error updating status of statefulset migration `%s` in `%s` namespace, error: %s",
+			sm.GetName(), sm.GetNamespace(), err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// getStatefulSetMigrationOwnerReferences returns the owner references of the
+// resources of the statefulset migration, they are garbage collected with it
+func getStatefulSetMigrationOwnerReferences(sm internalv1alpha1.StatefulSetMigration) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{
+		{
+			APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+			Kind:       SmKind,
+			Name:       sm.Name,
+			UID:        sm.UID,
+			Controller: &isController,
+		},
+	}
+}
+
+// getStatefulSetCmTemplate returns the configmap keeping the statefulset,
+// so that it can be created again after it is deleted
+func getStatefulSetCmTemplate(sm internalv1alpha1.StatefulSetMigration, data string) corev1.ConfigMap {
+	return corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      statefulSetMigrationNamePrefix + sm.Name,
+			Namespace: sm.Namespace,
+			Labels: map[string]string{
+				createdByLabel: componentName,
+				managedByLabel: componentName,
+				appLabel:       statefulSetMigrationNamePrefix + sm.Name,
+			},
+			OwnerReferences: getStatefulSetMigrationOwnerReferences(sm),
+		},
+		Data: map[string]string{
+			statefulSetKey: data,
+		},
+	}
+}
+
+// getMigrationDataPopulatorTemplate returns the data populator of a volume
+// of the statefulset, which rebinds the pvc once the data is populated
+func getMigrationDataPopulatorTemplate(sm internalv1alpha1.StatefulSetMigration,
+	vol internalv1alpha1.StatefulSetMigrationVolume, pvc *corev1.PersistentVolumeClaim) internalv1alpha1.DataPopulator {
+	storageClassName := sm.Spec.StorageClassName
+	return internalv1alpha1.DataPopulator{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       DpKind,
+			APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      vol.DataPopulator,
+			Namespace: sm.Namespace,
+			Labels: map[string]string{
+				createdByLabel: componentName,
+				managedByLabel: componentName,
+				appLabel:       statefulSetMigrationNamePrefix + sm.Name,
+			},
+			OwnerReferences: getStatefulSetMigrationOwnerReferences(sm),
+		},
+		Spec: internalv1alpha1.DataPopulatorSpec{
+			SourcePVC:          pvc.Name,
+			SourcePVCNamespace: sm.Namespace,
+			DestinationPVC: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      pvc.Spec.AccessModes,
+				Resources:        pvc.Spec.Resources,
+				VolumeMode:       pvc.Spec.VolumeMode,
+				StorageClassName: &storageClassName,
+			},
+			RebindSourcePVC: true,
+		},
+	}
+}
+
+// getRecreatedStatefulSetTemplate returns the statefulset to be created in
+// place of the deleted one, its migrated volume claim templates use the new
+// storage class
+func getRecreatedStatefulSetTemplate(sm internalv1alpha1.StatefulSetMigration,
+	sts *appsv1.StatefulSet) appsv1.StatefulSet {
+	replicas := sm.Status.Replicas
+	spec := *sts.Spec.DeepCopy()
+	spec.Replicas = &replicas
+	for i := range spec.VolumeClaimTemplates {
+		tmpl := &spec.VolumeClaimTemplates[i]
+		for _, vol := range sm.Status.Volumes {
+			if vol.VolumeClaimTemplate == tmpl.Name {
+				storageClassName := sm.Spec.StorageClassName
+				tmpl.Spec.StorageClassName = &storageClassName
+				break
+			}
+		}
+	}
+	return appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       statefulSetKind,
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        sts.Name,
+			Namespace:   sts.Namespace,
+			Labels:      sts.Labels,
+			Annotations: sts.Annotations,
+		},
+		Spec: spec,
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+const (
+	// Prefixes of the names of the populator pod and the pvc it populates,
+	// they are created by the rsync populator for every destination pvc.
+	populatorPodPrefix = "populate-"
+	populatorPVCPrefix = "prime-"
+)
+
+// RsyncPopulatorNamespace is the namespace where the rsync populator runs the populator pods
+var RsyncPopulatorNamespace string
+
+// suspendDataPopulator stops the transfer of the data populator, the copied
+// data is kept till it is resumed
+func (c *controller) suspendDataPopulator(ctx context.Context, source *controller, dp *internalv1alpha1.DataPopulator,
+	dptc *templateConfig, destinationPVC *corev1.PersistentVolumeClaim) error {
+	if err := c.stopTransfer(ctx, source, dp, dptc, destinationPVC); err != nil {
+		return err
+	}
+
+	// The transfer is checked against the concurrency limits again when it is resumed
+	clone := dp.DeepCopy()
+	clone.Status.State = internalv1alpha1.StatusSuspended
+	clone.Status.Message = "suspended, the copied data is kept in pvc `" + populatorPVCPrefix +
+		string(destinationPVC.UID) + "` in `" + RsyncPopulatorNamespace + "` namespace"
+	clone.Status.QueuePosition = 0
+	clone.Status.Transfer = nil
+	if equality.Semantic.DeepEqual(clone.Status, dp.Status) {
+		return nil
+	}
+	if err := c.updateDataPopulator(clone); err != nil {
+		return fmt.Errorf("error updating status of data populator `%s` in `%s` namespace, error: %s",
+			dp.GetName(), dp.GetNamespace(), err)
+	}
+	c.requeuePendingTransfers()
+	return nil
+}
+
+// stopTransfer deletes the rsync daemons of all the sources and the populator
+// pod, the rsync populator keeps the pvc which is being populated. The rsync
+// populator resource is deleted first, so that it doesn't create the
+// populator pod again.
+func (c *controller) stopTransfer(ctx context.Context, source *controller, dp *internalv1alpha1.DataPopulator,
+	dptc *templateConfig, destinationPVC *corev1.PersistentVolumeClaim) error {
+	if err := source.ensureRsyncDaemon(false, dptc, dptc.sourcePVCNamespace); err != nil {
+		return err
+	}
+	rsyncPopulatorTemplate := dptc.getRsyncPopulatorTemplate()
+	if err := c.ensurePopulator(false, dp.Namespace, &rsyncPopulatorTemplate); err != nil {
+		return fmt.Errorf("error ensuring(false) populator `%s` in `%s` namespace, error: %s",
+			rsyncPopulatorTemplate.GetName(), dp.Namespace, err)
+	}
+	podName := populatorPodPrefix + string(destinationPVC.UID)
+	err := c.kubeClient.CoreV1().Pods(RsyncPopulatorNamespace).Delete(ctx, podName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error deleting populator pod `%s` in `%s` namespace error: %s",
+			podName, RsyncPopulatorNamespace, err)
+	}
+	return nil
+}
+
+// observeTransferPods records the uids of the rsync daemon pods and the
+// populator pod of the transfer, and returns true if any of them was
+// recreated after it was transferring data. The daemon pods are recorded
+// once they are ready, and the populator pod once it is running while all
+// of the daemon pods are ready, so that a pod which fails before the data is
+// transferred, like the populator pod which can't connect to the daemon
+// which isn't ready yet, is not counted. A pod which is not found is not
+// recorded, so that the pod which is created again is counted once.
+func (c *controller) observeTransferPods(ctx context.Context, source *controller, dptc *templateConfig,
+	destinationPVC *corev1.PersistentVolumeClaim, transfer *internalv1alpha1.TransferStatus) (bool, error) {
+	interrupted := false
+
+	// Every source which is merged into the destination pvc has its own rsync daemon
+	daemons := []*templateConfig{dptc}
+	if len(dptc.sources) > 0 {
+		daemons = dptc.sources
+	}
+	uids := []string{}
+	for _, stc := range daemons {
+		pod, err := source.getTransferPod(ctx, stc.sourcePVCNamespace, stc.name)
+		if err != nil {
+			return false, err
+		}
+		if pod == nil || !isPodReady(pod) {
+			uids = nil
+			break
+		}
+		uids = append(uids, string(pod.UID))
+	}
+	if uids != nil {
+		daemonPodUID := strings.Join(uids, ",")
+		if transfer.DaemonPodUID != "" && transfer.DaemonPodUID != daemonPodUID {
+			interrupted = true
+		}
+		transfer.DaemonPodUID = daemonPodUID
+	}
+
+	pod, err := c.getTransferPod(ctx, RsyncPopulatorNamespace, populatorPodPrefix+string(destinationPVC.UID))
+	if err != nil {
+		return false, err
+	}
+	if pod != nil && uids != nil && pod.GetDeletionTimestamp() == nil && pod.Status.Phase == corev1.PodRunning {
+		if transfer.PopulatorPodUID != "" && transfer.PopulatorPodUID != string(pod.UID) {
+			interrupted = true
+		}
+		transfer.PopulatorPodUID = string(pod.UID)
+	}
+	return interrupted, nil
+}
+
+// getTransferPod returns the pod, or nil if it is not found
+func (c *controller) getTransferPod(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
+	pod, err := c.kubeClient.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting pod `%s` in `%s` namespace error: %s", name, namespace, err)
+	}
+	return pod, nil
+}
+
+// isPodReady returns true if the pod is running and ready
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.GetDeletionTimestamp() != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
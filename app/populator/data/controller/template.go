@@ -17,37 +17,193 @@ limitations under the License.
 package controller
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	"github.com/openebs/data-populator/pkg/rsync"
 )
 
 var (
 	RsyncServerImage string
+	// RsyncClientImage is used by the export jobs to rsync and ssh targets
+	RsyncClientImage string
+	// RcloneClientImage is used by the export jobs to object storage targets
+	RcloneClientImage string
 )
 
 type templateConfig struct {
+	// name of the rsync daemon resources
+	name               string
 	sourcePVCName      string
 	sourcePVCNamespace string
 	destinationPVCSpec corev1.PersistentVolumeClaimSpec
 	imageName          string
 	rsyncPassword      string
 	rsyncUsername      string
+	// credentialsSecret is the secret having the password of the rsync
+	// user and the tls certificate, rsyncPassword is used if it is not set.
+	credentialsSecret string
+	readOnly          bool
+	tls               bool
+	serviceType       corev1.ServiceType
+	// allowedSourceRanges are the CIDRs of the clients allowed to connect
+	// to the rsync daemon, every client is allowed if it is empty
+	allowedSourceRanges []string
+	ownerReferences     []metav1.OwnerReference
+	// connectionSecret is the secret having the connection details of the
+	// rsync daemon, it is used by the rsync populator when it is set.
+	connectionSecret string
+	// nodeName is set to run the rsync daemon on the node where the source
+	// pvc is already mounted
+	nodeName string
+	// bandwidthLimit is the rate in bytes per second at which the rsync
+	// daemon sends the data, it is not limited if it is 0
+	bandwidthLimit int64
+	// populatorBandwidthLimit is the bandwidth limit of the rsync populator
+	populatorBandwidthLimit *resource.Quantity
+	// rsyncOptions are the rsync behaviours used to copy the data
+	rsyncOptions *internalv1alpha1.RsyncOptions
+	// sourceSubPath is the path inside the source pvc which is served by the
+	// rsync daemon, destinationSubPath is the path inside the destination pvc
+	// into which it is copied
+	sourceSubPath      string
+	destinationSubPath string
+	include            []string
+	exclude            []string
+	// destinationPVCName is the name of the pvc into which the data is populated
+	destinationPVCName string
+	// sources are the configs of the rsync daemons of the sources which are
+	// merged into the destination pvc, name is then the rsync populator's
+	sources []*templateConfig
 }
 
 func templateFromDataPopulator(cr internalv1alpha1.DataPopulator) (*templateConfig, error) {
 	tc := &templateConfig{
+		name:               RsyncNamePrefix + cr.Spec.SourcePVC,
 		sourcePVCName:      cr.Spec.SourcePVC,
 		sourcePVCNamespace: cr.Spec.SourcePVCNamespace,
 		destinationPVCSpec: cr.Spec.DestinationPVC,
 		imageName:          RsyncServerImage,
 		rsyncUsername:      rsyncUsername,
 		rsyncPassword:      rsyncPassword,
+		rsyncOptions:       cr.Spec.RsyncOptions,
+		include:            cr.Spec.Include,
+		exclude:            cr.Spec.Exclude,
+	}
+	// The sub paths are validated before the data populator is synced
+	tc.sourceSubPath, _ = rsync.CleanSubPath(cr.Spec.SourceSubPath)
+	tc.destinationSubPath, _ = rsync.CleanSubPath(cr.Spec.DestinationSubPath)
+	tc.destinationPVCName = cr.Spec.SourcePVC + "-populated"
+	if len(cr.Spec.Sources) > 0 {
+		// Every source has its own rsync daemon, all of them are copied by
+		// the rsync populator of the data populator
+		tc.name = RsyncNamePrefix + cr.Name
+		tc.sourcePVCName = ""
+		tc.destinationPVCName = cr.Name + "-populated"
+		for _, source := range cr.Spec.Sources {
+			stc := *tc
+			stc.name = RsyncNamePrefix + source.PVC
+			stc.sourcePVCName = source.PVC
+			stc.sourcePVCNamespace = getSourceNamespace(&cr, source)
+			stc.sourceSubPath, _ = rsync.CleanSubPath(source.SubPath)
+			stc.destinationSubPath, _ = rsync.CleanSubPath(source.DestinationSubPath)
+			if stc.destinationSubPath == "" {
+				stc.destinationSubPath = source.PVC
+			}
+			stc.sources = nil
+			tc.sources = append(tc.sources, &stc)
+		}
+	}
+	if cr.Spec.BandwidthLimit != nil {
+		tc.bandwidthLimit = cr.Spec.BandwidthLimit.Value()
+		tc.populatorBandwidthLimit = cr.Spec.BandwidthLimit
+	}
+	if cr.Spec.SourceCluster != nil {
+		// The daemon of another cluster is reached over the network of
+		// both the clusters, so it is exposed read-only over TLS.
+		tc.credentialsSecret = tc.name
+		tc.connectionSecret = tc.name
+		tc.readOnly = true
+		tc.tls = true
+		tc.serviceType = cr.Spec.SourceCluster.ServiceType
+		if tc.serviceType == "" {
+			tc.serviceType = corev1.ServiceTypeLoadBalancer
+		}
 	}
 	return tc, nil
 }
 
+// validateDataPopulator returns an error if any of the sub paths of the
+// data populator is not inside its volume, or if the sources are set with
+// what is not supported for them
+func validateDataPopulator(cr *internalv1alpha1.DataPopulator) error {
+	if _, err := rsync.CleanSubPath(cr.Spec.SourceSubPath); err != nil {
+		return fmt.Errorf("invalid sourceSubPath: %s", err)
+	}
+	if _, err := rsync.CleanSubPath(cr.Spec.DestinationSubPath); err != nil {
+		return fmt.Errorf("invalid destinationSubPath: %s", err)
+	}
+	if len(cr.Spec.Sources) == 0 {
+		if cr.Spec.SourcePVC == "" {
+			return fmt.Errorf("either sourcePVC or sources must be set")
+		}
+		return nil
+	}
+
+	switch {
+	case cr.Spec.SourcePVC != "":
+		return fmt.Errorf("sourcePVC can't be set with sources")
+	case cr.Spec.SourceSubPath != "":
+		return fmt.Errorf("sourceSubPath can't be set with sources, the subPath of the sources is used")
+	case cr.Spec.SourceCluster != nil || cr.Spec.Live != nil || cr.Spec.RebindSourcePVC:
+		return fmt.Errorf("sourceCluster, live and rebindSourcePVC are not supported with sources")
+	}
+	seen := map[string]bool{}
+	for i, source := range cr.Spec.Sources {
+		if source.PVC == "" {
+			return fmt.Errorf("pvc of source %d is not set", i)
+		}
+		// The rsync daemon of a source is named after its pvc
+		key := getSourceNamespace(cr, source) + "/" + source.PVC
+		if seen[key] {
+			return fmt.Errorf("source pvc `%s` is set more than once", key)
+		}
+		seen[key] = true
+		if _, err := rsync.CleanSubPath(source.SubPath); err != nil {
+			return fmt.Errorf("invalid subPath of source %d: %s", i, err)
+		}
+		if _, err := rsync.CleanSubPath(source.DestinationSubPath); err != nil {
+			return fmt.Errorf("invalid destinationSubPath of source %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// getSourceNamespace returns the namespace of the source pvc
+func getSourceNamespace(cr *internalv1alpha1.DataPopulator, source internalv1alpha1.DataPopulatorSource) string {
+	if source.Namespace != "" {
+		return source.Namespace
+	}
+	if cr.Spec.SourcePVCNamespace != "" {
+		return cr.Spec.SourcePVCNamespace
+	}
+	return cr.Namespace
+}
+
+// getSourceConfigs returns the configs of the rsync daemons of the sources
+func (tc *templateConfig) getSourceConfigs() []*templateConfig {
+	if len(tc.sources) > 0 {
+		return tc.sources
+	}
+	return []*templateConfig{tc}
+}
+
 // getDestinationPVCTemplate returns destination pvc object
 // To the destination pvc object add the following:
 // 1. add created by label
@@ -59,7 +215,7 @@ func (tc *templateConfig) getDestinationPVCTemplate() corev1.PersistentVolumeCla
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: tc.sourcePVCName + "-populated",
+			Name: tc.destinationPVCName,
 			Labels: map[string]string{
 				createdByLabel: componentName,
 			},
@@ -74,7 +230,7 @@ func (tc *templateConfig) getDestinationPVCTemplate() corev1.PersistentVolumeCla
 			name := GroupOpenebsIO
 			return &name
 		}(),
-		Name: RsyncNamePrefix + tc.sourcePVCName,
+		Name: tc.name,
 	}
 
 	return destinationPvc
@@ -87,7 +243,7 @@ func (tc *templateConfig) getRsyncPopulatorTemplate() internalv1alpha1.RsyncPopu
 			APIVersion: GroupOpenebsIO + "/" + VersionV1alpha1,
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: RsyncNamePrefix + tc.sourcePVCName,
+			Name: tc.name,
 			Labels: map[string]string{
 				roleLabel:      populatorName,
 				createdByLabel: componentName,
@@ -98,13 +254,49 @@ func (tc *templateConfig) getRsyncPopulatorTemplate() internalv1alpha1.RsyncPopu
 			Username: tc.rsyncUsername,
 			Password: tc.rsyncPassword,
 			Path:     SourcePvcMountPath,
-			URL:      RsyncNamePrefix + tc.sourcePVCName + "." + tc.sourcePVCNamespace + ":873",
+			URL:      tc.name + "." + tc.sourcePVCNamespace + ":" + strconv.Itoa(rsyncPort),
 		},
 	}
+	if tc.connectionSecret != "" {
+		populator.Spec = internalv1alpha1.RsyncPopulatorSpec{
+			ConnectionSecret: tc.connectionSecret,
+		}
+	}
+	for _, source := range tc.sources {
+		populator.Spec.Sources = append(populator.Spec.Sources, internalv1alpha1.RsyncPopulatorSource{
+			URL:     source.name + "." + source.sourcePVCNamespace + ":" + strconv.Itoa(rsyncPort),
+			Path:    SourcePvcMountPath,
+			SubPath: source.destinationSubPath,
+		})
+	}
+	if len(tc.sources) > 0 {
+		populator.Spec.URL = ""
+		populator.Spec.Path = ""
+	}
+	populator.Spec.BandwidthLimit = tc.populatorBandwidthLimit
+	populator.Spec.Options = tc.rsyncOptions
+	populator.Spec.SubPath = tc.destinationSubPath
+	populator.Spec.Include = tc.include
+	populator.Spec.Exclude = tc.exclude
 	return populator
 }
 
 func (tc *templateConfig) getCmTemplate() corev1.ConfigMap {
+	// With tls the daemon only listens for the connections from stunnel
+	address := ""
+	hostsAllow := "0.0.0.0/0"
+	if len(tc.allowedSourceRanges) > 0 {
+		hostsAllow = strings.Join(tc.allowedSourceRanges, " ")
+	}
+	if tc.tls {
+		address = "address = 127.0.0.1"
+		hostsAllow = "127.0.0.1"
+	}
+	// The module serves only the sub path of the source pvc
+	modulePath := SourcePvcMountPath
+	if tc.sourceSubPath != "" {
+		modulePath += "/" + tc.sourceSubPath
+	}
 	var rsyncdconfig = `
 # /etc/rsyncd.conf
 
@@ -118,11 +310,12 @@ uid = 0
 gid = 0
 use chroot = yes
 reverse lookup = no
+` + address + `
 [data]
     hosts deny = *
-    hosts allow = 0.0.0.0/0
-    read only = false
-    path = ` + SourcePvcMountPath + `
+    hosts allow = ` + hostsAllow + `
+    read only = ` + strconv.FormatBool(tc.readOnly) + `
+    path = ` + modulePath + `
     auth users = , ` + tc.rsyncUsername + `:rw
     secrets file = /etc/rsyncd.secrets
     timeout = 600
@@ -134,34 +327,64 @@ reverse lookup = no
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: RsyncNamePrefix + tc.sourcePVCName,
+			Name: tc.name,
 			Labels: map[string]string{
 				createdByLabel: componentName,
 				managedByLabel: componentName,
 				roleLabel:      roleLabelValue,
 			},
+			OwnerReferences: tc.ownerReferences,
 		},
 		Data: map[string]string{
 			"rsyncd.conf": rsyncdconfig,
 		},
 	}
+
+	if tc.tls {
+		cm.Data["stunnel.conf"] = `
+foreground = yes
+pid =
+[rsync]
+accept = ` + strconv.Itoa(rsyncTLSPort) + `
+connect = 127.0.0.1:` + strconv.Itoa(rsyncPort) + `
+cert = ` + rsyncTLSMountPath + `/` + corev1.TLSCertKey + `
+key = ` + rsyncTLSMountPath + `/` + corev1.TLSPrivateKeyKey + `
+`
+	}
 	return cm
 }
 
 func (tc *templateConfig) getPodTemplate() corev1.Pod {
+	password := corev1.EnvVar{
+		Name:  "RSYNC_PASSWORD",
+		Value: tc.rsyncPassword,
+	}
+	if tc.credentialsSecret != "" {
+		password = corev1.EnvVar{
+			Name: "RSYNC_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: tc.credentialsSecret},
+					Key:                  passwordKey,
+				},
+			},
+		}
+	}
+
 	pod := corev1.Pod{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Pod",
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: RsyncNamePrefix + tc.sourcePVCName,
+			Name: tc.name,
 			Labels: map[string]string{
 				createdByLabel: componentName,
 				managedByLabel: componentName,
-				appLabel:       RsyncNamePrefix + tc.sourcePVCName,
+				appLabel:       tc.name,
 				roleLabel:      roleLabelValue,
 			},
+			OwnerReferences: tc.ownerReferences,
 		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
@@ -170,10 +393,7 @@ func (tc *templateConfig) getPodTemplate() corev1.Pod {
 					Image:           tc.imageName,
 					ImagePullPolicy: corev1.PullAlways,
 					Env: []corev1.EnvVar{
-						{
-							Name:  "RSYNC_PASSWORD",
-							Value: tc.rsyncPassword,
-						},
+						password,
 						{
 							Name:  "RSYNC_USERNAME",
 							Value: tc.rsyncUsername,
@@ -181,13 +401,14 @@ func (tc *templateConfig) getPodTemplate() corev1.Pod {
 					},
 					Ports: []corev1.ContainerPort{
 						{
-							ContainerPort: 873,
+							ContainerPort: rsyncPort,
 						},
 					},
 					VolumeMounts: []corev1.VolumeMount{
 						{
 							Name:      "data",
 							MountPath: SourcePvcMountPath,
+							ReadOnly:  tc.readOnly,
 						},
 						{
 							Name:      "config",
@@ -203,6 +424,7 @@ func (tc *templateConfig) getPodTemplate() corev1.Pod {
 					VolumeSource: corev1.VolumeSource{
 						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 							ClaimName: tc.sourcePVCName,
+							ReadOnly:  tc.readOnly,
 						},
 					},
 				},
@@ -211,45 +433,110 @@ func (tc *templateConfig) getPodTemplate() corev1.Pod {
 					VolumeSource: corev1.VolumeSource{
 						ConfigMap: &corev1.ConfigMapVolumeSource{
 							LocalObjectReference: corev1.LocalObjectReference{
-								Name: RsyncNamePrefix + tc.sourcePVCName,
+								Name: tc.name,
 							},
 						},
 					},
 				},
 			},
+			NodeName:      tc.nodeName,
 			RestartPolicy: corev1.RestartPolicyNever,
 		},
 	}
+
+	if tc.bandwidthLimit > 0 {
+		// The command of the image is run with the limit, rsync takes it in KiB per second
+		pod.Spec.Containers[0].Args = []string{"/usr/bin/rsync", "--no-detach", "--daemon",
+			"--log-file=/dev/stdout", "--bwlimit=" + strconv.FormatInt((tc.bandwidthLimit+1023)/1024, 10)}
+		pod.Annotations = map[string]string{bandwidthLimitAnnotation: strconv.FormatInt(tc.bandwidthLimit, 10)}
+	}
+
+	if tc.tls {
+		// stunnel terminates tls and forwards the connections to the daemon
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+			Name:            "stunnel",
+			Image:           tc.imageName,
+			ImagePullPolicy: corev1.PullAlways,
+			Command:         []string{"stunnel", "/etc/stunnel/stunnel.conf"},
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: rsyncTLSPort,
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "config",
+					MountPath: "/etc/stunnel/stunnel.conf",
+					SubPath:   "stunnel.conf",
+				},
+				{
+					Name:      "tls",
+					MountPath: rsyncTLSMountPath,
+					ReadOnly:  true,
+				},
+			},
+		})
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: "tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: tc.credentialsSecret,
+					Items: []corev1.KeyToPath{
+						{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+						{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+					},
+				},
+			},
+		})
+	}
 	return pod
 }
 
 func (tc *templateConfig) getSvcTemplate() corev1.Service {
+	port := corev1.ServicePort{
+		Name:     "rsync-daemon",
+		Port:     rsyncPort,
+		Protocol: corev1.ProtocolTCP,
+	}
+	if tc.tls {
+		port = corev1.ServicePort{
+			Name:     "rsync-tls",
+			Port:     rsyncTLSPort,
+			Protocol: corev1.ProtocolTCP,
+		}
+	}
+
 	svc := corev1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: RsyncNamePrefix + tc.sourcePVCName,
+			Name: tc.name,
 			Labels: map[string]string{
 				createdByLabel: componentName,
 				managedByLabel: componentName,
 				roleLabel:      roleLabelValue,
 			},
+			OwnerReferences: tc.ownerReferences,
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
-				{
-					Name:     "rsync-daemon",
-					Port:     873,
-					Protocol: corev1.ProtocolTCP,
-				},
-			},
+			Type:  tc.serviceType,
+			Ports: []corev1.ServicePort{port},
 			Selector: map[string]string{
-				appLabel:  RsyncNamePrefix + tc.sourcePVCName,
+				appLabel:  tc.name,
 				roleLabel: roleLabelValue,
 			},
 		},
 	}
+	if tc.serviceType == corev1.ServiceTypeLoadBalancer {
+		svc.Spec.LoadBalancerSourceRanges = tc.allowedSourceRanges
+	}
+	if len(tc.allowedSourceRanges) > 0 && (tc.serviceType == corev1.ServiceTypeNodePort ||
+		tc.serviceType == corev1.ServiceTypeLoadBalancer) {
+		// The external traffic is not masqueraded only with the local policy,
+		// otherwise the daemon sees the address of a node instead of the client.
+		svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	}
 	return svc
 }
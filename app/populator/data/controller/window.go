@@ -0,0 +1,75 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// DefaultTransferWindow is the transfer window of the data populators which
+// don't have one, the transfers may be started anytime if its start is not set
+var DefaultTransferWindow internalv1alpha1.TransferWindow
+
+// getTransferWindow returns the transfer window of the data populator, it is
+// nil if the transfers may be started anytime
+func getTransferWindow(dp *internalv1alpha1.DataPopulator) *internalv1alpha1.TransferWindow {
+	if dp.Spec.TransferWindow != nil {
+		return dp.Spec.TransferWindow
+	}
+	if DefaultTransferWindow.Start == "" {
+		return nil
+	}
+	return &DefaultTransferWindow
+}
+
+// getTransferWindowStatus returns whether the window is open at the given
+// time, and when it closes if it is open or opens if it is closed
+func getTransferWindowStatus(window *internalv1alpha1.TransferWindow,
+	now time.Time) (*internalv1alpha1.TransferWindowStatus, error) {
+	start, err := cron.ParseStandard(window.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start `%s` of the transfer window: %s", window.Start, err)
+	}
+	end, err := cron.ParseStandard(window.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end `%s` of the transfer window: %s", window.End, err)
+	}
+	location := time.UTC
+	if window.TimeZone != "" {
+		location, err = time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone `%s` of the transfer window: %s", window.TimeZone, err)
+		}
+	}
+
+	// The window is open if it closes before it opens again
+	nextStart := start.Next(now.In(location))
+	nextEnd := end.Next(now.In(location))
+	if nextStart.IsZero() || nextEnd.IsZero() {
+		return nil, fmt.Errorf("transfer window from `%s` to `%s` never opens", window.Start, window.End)
+	}
+	if nextEnd.Before(nextStart) {
+		return &internalv1alpha1.TransferWindowStatus{Open: true, NextTransition: metav1.NewTime(nextEnd)}, nil
+	}
+	return &internalv1alpha1.TransferWindowStatus{Open: false, NextTransition: metav1.NewTime(nextStart)}, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func TestGetTransferWindowStatus(t *testing.T) {
+	nightly := internalv1alpha1.TransferWindow{Start: "0 22 * * *", End: "0 6 * * *"}
+	tests := map[string]struct {
+		window         internalv1alpha1.TransferWindow
+		now            time.Time
+		wantOpen       bool
+		wantTransition time.Time
+		wantErr        bool
+	}{
+		"open before midnight": {
+			window:         nightly,
+			now:            time.Date(2022, 3, 1, 23, 0, 0, 0, time.UTC),
+			wantOpen:       true,
+			wantTransition: time.Date(2022, 3, 2, 6, 0, 0, 0, time.UTC),
+		},
+		"open after midnight": {
+			window:         nightly,
+			now:            time.Date(2022, 3, 2, 1, 0, 0, 0, time.UTC),
+			wantOpen:       true,
+			wantTransition: time.Date(2022, 3, 2, 6, 0, 0, 0, time.UTC),
+		},
+		"closed during the day": {
+			window:         nightly,
+			now:            time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC),
+			wantOpen:       false,
+			wantTransition: time.Date(2022, 3, 1, 22, 0, 0, 0, time.UTC),
+		},
+		"closed when it closes": {
+			window:         nightly,
+			now:            time.Date(2022, 3, 2, 6, 0, 0, 0, time.UTC),
+			wantOpen:       false,
+			wantTransition: time.Date(2022, 3, 2, 22, 0, 0, 0, time.UTC),
+		},
+		"in the time zone of the window": {
+			window: internalv1alpha1.TransferWindow{Start: "0 22 * * *", End: "0 6 * * *",
+				TimeZone: "Europe/Berlin"},
+			now:            time.Date(2022, 3, 1, 21, 30, 0, 0, time.UTC),
+			wantOpen:       true,
+			wantTransition: time.Date(2022, 3, 2, 5, 0, 0, 0, time.UTC),
+		},
+		"invalid start": {
+			window:  internalv1alpha1.TransferWindow{Start: "not a schedule", End: "0 6 * * *"},
+			wantErr: true,
+		},
+		"invalid end": {
+			window:  internalv1alpha1.TransferWindow{Start: "0 22 * * *", End: "0 25 * * *"},
+			wantErr: true,
+		},
+		"invalid time zone": {
+			window:  internalv1alpha1.TransferWindow{Start: "0 22 * * *", End: "0 6 * * *", TimeZone: "Nowhere/City"},
+			wantErr: true,
+		},
+		"never opens": {
+			window:  internalv1alpha1.TransferWindow{Start: "0 0 30 2 *", End: "0 6 * * *"},
+			now:     time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, err := getTransferWindowStatus(&test.window, test.now)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("getTransferWindowStatus() error = %v, wantErr %t", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if status.Open != test.wantOpen {
+				t.Errorf("getTransferWindowStatus() open = %t, want %t", status.Open, test.wantOpen)
+			}
+			if !status.NextTransition.Time.Equal(test.wantTransition) {
+				t.Errorf("getTransferWindowStatus() next transition = %s, want %s",
+					status.NextTransition.Time, test.wantTransition)
+			}
+		})
+	}
+}
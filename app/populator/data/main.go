@@ -32,6 +32,30 @@ func main() {
 	klog.InitFlags(nil)
 
 	flag.StringVar(&controller.RsyncServerImage, "image-name", "", "Rsync server image to use as data source")
+	flag.StringVar(&controller.RsyncClientImage, "rsync-client-image-name", "",
+		"Rsync client image to use for exporting to rsync and ssh targets")
+	flag.StringVar(&controller.RcloneClientImage, "rclone-client-image-name", "",
+		"Rclone client image to use for exporting to object storage targets")
+	flag.StringVar(&controller.RsyncPopulatorNamespace, "rsync-populator-namespace", "openebs-data-population",
+		"Namespace of the rsync populator, where it runs the populator pods")
+	flag.IntVar(&controller.MaxActiveTransfers, "max-active-transfers", 0,
+		"Number of data populators copying data at a time, 0 is unlimited")
+	flag.IntVar(&controller.MaxSourceNodeTransfers, "max-transfers-per-source-node", 0,
+		"Number of data populators copying data from the volumes of a node at a time, 0 is unlimited")
+	flag.IntVar(&controller.MaxDestinationNodeTransfers, "max-transfers-per-destination-node", 0,
+		"Number of data populators copying data into the volumes of a node at a time, 0 is unlimited")
+	flag.IntVar(&controller.MaxStorageClassTransfers, "max-transfers-per-storage-class", 0,
+		"Number of data populators copying data into the volumes of a storage class at a time, 0 is unlimited")
+	flag.StringVar(&controller.MaxAggregateBandwidth, "max-aggregate-bandwidth", "",
+		"Rate in bytes per second, like 100Mi, of all the data populators copying data, it is unlimited if not set")
+	flag.StringVar(&controller.DefaultTransferWindow.Start, "transfer-window-start", "",
+		"Cron schedule of when the transfer window opens, the transfers may be started anytime if it is not set")
+	flag.StringVar(&controller.DefaultTransferWindow.End, "transfer-window-end", "",
+		"Cron schedule of when the transfer window closes")
+	flag.StringVar(&controller.DefaultTransferWindow.TimeZone, "transfer-window-timezone", "",
+		"Time zone of the transfer window schedules, defaults to UTC")
+	flag.BoolVar(&controller.DefaultTransferWindow.PauseOutsideWindow, "pause-transfers-outside-window", false,
+		"Pause the running transfers when the transfer window closes, and resume them when it opens")
 
 	var kubeconfig *string
 	if home := homedir.HomeDir(); home != "" {
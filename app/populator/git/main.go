@@ -0,0 +1,265 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	populator_machinery "github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/secret"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	prefix     = "openebs.io"
+	mountPath  = "/mnt"
+	devicePath = "/dev/block"
+
+	groupName  = "openebs.io"
+	apiVersion = "v1alpha1"
+	kind       = "GitPopulator"
+	resource   = "gitpopulators"
+
+	// Keys of the credentials secret, these are the same as the keys
+	// of the kubernetes.io/basic-auth and kubernetes.io/ssh-auth secrets.
+	usernameKey   = "username"
+	passwordKey   = "password"
+	sshKey        = "ssh-privatekey"
+	knownHostsKey = "known_hosts"
+
+	// The credentials are read by git and ssh from the files of the
+	// secret of the pod, so that they are not in the spec of the pod
+	// or written into the volume.
+	credentialsVolumeName = "credentials"
+	credentialsMountPath  = "/etc/git-populator"
+	askPassPath           = "/tmp/git-askpass"
+)
+
+var (
+	gk  = schema.GroupKind{Group: groupName, Kind: kind}
+	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	kubeClient kubernetes.Interface
+
+	imageName string
+
+	commitRegex = regexp.MustCompile(`^[a-fA-F0-9]{7,40}$`)
+)
+
+func main() {
+	klog.InitFlags(nil)
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		panic(err)
+	}
+
+	flag.StringVar(&imageName, "image-name", "", "Image to use for populating")
+	flag.Parse()
+
+	namespace := os.Getenv("POD_NAMESPACE")
+
+	// The client is used to read the credentials secret of the populators
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+	kubeClient, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	populator_machinery.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
+}
+
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator_machinery.Pod, error) {
+	if rawBlock {
+		return nil, fmt.Errorf("block volumes are not supported by %s", kind)
+	}
+
+	populator := internalv1alpha1.GitPopulator{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(u.UnstructuredContent(), &populator)
+	if err != nil {
+		return nil, err
+	}
+	spec := populator.Spec
+	if spec.Repository == "" {
+		return nil, fmt.Errorf("repository is not set in %s `%s`", kind, populator.GetName())
+	}
+	if spec.Commit != "" && !commitRegex.MatchString(spec.Commit) {
+		return nil, fmt.Errorf("invalid commit `%s` in %s `%s`", spec.Commit, kind, populator.GetName())
+	}
+	// The remote is kept in .git/config of the volume
+	if repo, err := url.Parse(spec.Repository); err == nil && repo.User != nil {
+		if _, ok := repo.User.Password(); ok {
+			return nil, fmt.Errorf("repository of %s `%s` must not have a password, please use the credentials secret",
+				kind, populator.GetName())
+		}
+	}
+
+	pod := &populator_machinery.Pod{}
+	container := corev1.Container{
+		Name:  populator_machinery.ContainerName,
+		Image: imageName,
+	}
+	script := &shell.Script{}
+	// The volume is not empty for some filesystems(lost+found), so
+	// the repository is initialized in place instead of cloning. The volume
+	// is kept when the populator pod is run again, so the repository may be
+	// initialized already by the pod which failed.
+	script.Run("cd", mountPath).
+		Run("git", "init", "--quiet").
+		Raw("git remote add origin " + shell.Quote(spec.Repository) +
+			" || git remote set-url origin " + shell.Quote(spec.Repository))
+
+	if spec.CredentialsSecret != "" {
+		if err := addCredentials(script, pod, &container, populator); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(spec.SparseCheckoutPaths) > 0 {
+		// Both of them replace the sparse checkout of the pod which failed
+		script.Run("git", "sparse-checkout", "init").
+			Run(append([]string{"git", "sparse-checkout", "set", "--"}, spec.SparseCheckoutPaths...)...)
+	}
+
+	depth := []string{}
+	if spec.Depth != nil {
+		depth = []string{"--depth", strconv.Itoa(int(*spec.Depth))}
+	}
+
+	// The files of a checkout which failed are overwritten
+	switch {
+	case spec.Ref != "":
+		script.Run(append(append([]string{"git", "fetch", "origin"}, depth...), spec.Ref)...)
+		if spec.Commit != "" {
+			script.Run("git", "checkout", "--quiet", "--force", "--detach", spec.Commit)
+		} else {
+			script.Run("git", "checkout", "--quiet", "--force", "--detach", "FETCH_HEAD")
+		}
+	case spec.Commit != "":
+		// Not every server allows fetching a commit directly, in which
+		// case the whole repository is fetched.
+		fetchCommit := []string{}
+		for _, w := range append(append([]string{"git", "fetch", "origin"}, depth...), spec.Commit) {
+			fetchCommit = append(fetchCommit, shell.Quote(w))
+		}
+		script.Raw(strings.Join(fetchCommit, " ")+" || git fetch origin").
+			Run("git", "checkout", "--quiet", "--force", "--detach", spec.Commit)
+	default:
+		script.Run(append(append([]string{"git", "fetch", "origin"}, depth...), "HEAD")...).
+			Run("git", "checkout", "--quiet", "--force", "--detach", "FETCH_HEAD")
+	}
+
+	if spec.Commit != "" {
+		// Make sure that the requested commit is the one checked out
+		script.Raw(`case "$(git rev-parse HEAD)" in ` + shell.Quote(strings.ToLower(spec.Commit)) +
+			`*) ;; *) echo "checked out commit does not match" >&2; exit 1 ;; esac`)
+	}
+
+	if spec.Submodules {
+		script.Run(append([]string{"git", "submodule", "update", "--init", "--recursive"}, depth...)...)
+	}
+	script.Run("git", "log", "-1", "--oneline")
+
+	container.Args = script.Args()
+	pod.Spec.Containers = []corev1.Container{container}
+	return pod, nil
+}
+
+// addCredentials mounts the credentials from the secret into the
+// container, and sets the environment so that git uses them for https
+// or ssh.
+func addCredentials(script *shell.Script, pod *populator_machinery.Pod, container *corev1.Container,
+	populator internalv1alpha1.GitPopulator) error {
+	creds, err := secret.Get(kubeClient, populator.GetNamespace(), populator.Spec.CredentialsSecret)
+	if err != nil {
+		return err
+	}
+
+	// ssh refuses a private key which can be read by others
+	mode := int32(0400)
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: credentialsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  populator_machinery.SecretName,
+				DefaultMode: &mode,
+			},
+		},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      credentialsVolumeName,
+		MountPath: credentialsMountPath,
+		ReadOnly:  true,
+	})
+
+	if key, ok := creds[sshKey]; ok {
+		pod.SecretData = map[string][]byte{sshKey: []byte(key)}
+		sshCommand := "ssh -i " + credentialsMountPath + "/" + sshKey + " -o IdentitiesOnly=yes"
+		if knownHosts, ok := creds[knownHostsKey]; ok {
+			pod.SecretData[knownHostsKey] = []byte(knownHosts)
+			sshCommand += " -o UserKnownHostsFile=" + credentialsMountPath + "/" + knownHostsKey +
+				" -o StrictHostKeyChecking=yes"
+		} else {
+			sshCommand += " -o StrictHostKeyChecking=accept-new"
+		}
+		container.Env = append(container.Env, corev1.EnvVar{Name: "GIT_SSH_COMMAND", Value: sshCommand})
+		return nil
+	}
+
+	username, hasUsername := creds[usernameKey]
+	password, hasPassword := creds[passwordKey]
+	if !hasUsername || !hasPassword {
+		return fmt.Errorf("secret `%s` must have either the `%s` key or the `%s` and `%s` keys",
+			populator.Spec.CredentialsSecret, sshKey, usernameKey, passwordKey)
+	}
+	pod.SecretData = map[string][]byte{
+		usernameKey: []byte(username),
+		passwordKey: []byte(password),
+	}
+	// git asks the askpass program for the username and the password,
+	// the program only prints the mounted files so that the credentials
+	// are not written into the volume.
+	askPass := "#!/bin/sh\n" +
+		"case \"$1\" in\n" +
+		"Username*) cat " + credentialsMountPath + "/" + usernameKey + " ;;\n" +
+		"*) cat " + credentialsMountPath + "/" + passwordKey + " ;;\n" +
+		"esac\n"
+	script.Raw("printf '%s' " + shell.Quote(askPass) + " > " + askPassPath).
+		Raw("chmod 700 " + askPassPath)
+	container.Env = append(container.Env,
+		corev1.EnvVar{Name: "GIT_ASKPASS", Value: askPassPath},
+		corev1.EnvVar{Name: "GIT_TERMINAL_PROMPT", Value: "0"})
+	return nil
+}
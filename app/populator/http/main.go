@@ -0,0 +1,256 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	populator_machinery "github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/secret"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	prefix     = "openebs.io"
+	mountPath  = "/mnt"
+	devicePath = "/dev/block"
+
+	groupName  = "openebs.io"
+	apiVersion = "v1alpha1"
+	kind       = "HTTPPopulator"
+	resource   = "httppopulators"
+
+	// The archive is downloaded into an emptyDir before extracting it,
+	// so that it is not left in the volume if the populator fails.
+	archiveVolumeName = "archive"
+	archiveMountPath  = "/var/lib/http-populator"
+	archivePath       = archiveMountPath + "/archive"
+	defaultRetries    = 5
+
+	// The headers are read by curl from a file of the secret of the pod
+	headersVolumeName = "headers"
+	headersMountPath  = "/etc/http-populator"
+	headersKey        = "headers"
+
+	formatTar    = "tar"
+	formatTarGz  = "tar.gz"
+	formatTarZst = "tar.zst"
+	formatZip    = "zip"
+)
+
+var (
+	gk  = schema.GroupKind{Group: groupName, Kind: kind}
+	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	kubeClient kubernetes.Interface
+
+	imageName string
+
+	// formatSuffixes maps the file extensions to the archive format
+	formatSuffixes = map[string]string{
+		".tar":     formatTar,
+		".tar.gz":  formatTarGz,
+		".tgz":     formatTarGz,
+		".tar.zst": formatTarZst,
+		".tzst":    formatTarZst,
+		".zip":     formatZip,
+	}
+)
+
+func main() {
+	klog.InitFlags(nil)
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		panic(err)
+	}
+
+	flag.StringVar(&imageName, "image-name", "", "Image to use for populating")
+	flag.Parse()
+
+	namespace := os.Getenv("POD_NAMESPACE")
+
+	// The client is used to read the headers secret of the populators
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+	kubeClient, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	populator_machinery.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
+}
+
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator_machinery.Pod, error) {
+	if rawBlock {
+		return nil, fmt.Errorf("block volumes are not supported by %s", kind)
+	}
+
+	populator := internalv1alpha1.HTTPPopulator{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(u.UnstructuredContent(), &populator)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := url.ParseRequestURI(populator.Spec.URL); err != nil {
+		return nil, fmt.Errorf("invalid url in %s `%s` error: %s", kind, populator.GetName(), err)
+	}
+	if sum, err := hex.DecodeString(populator.Spec.SHA256); err != nil || len(sum) != 32 {
+		return nil, fmt.Errorf("invalid sha256 checksum in %s `%s`, it must be 64 hex characters",
+			kind, populator.GetName())
+	}
+	format, err := getFormat(populator.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("error getting archive format of %s `%s` error: %s",
+			kind, populator.GetName(), err)
+	}
+
+	retries := int32(defaultRetries)
+	if populator.Spec.Retries != nil {
+		retries = *populator.Spec.Retries
+	}
+
+	// curl retries on timeouts and on the 408, 429, 500, 502, 503
+	// and 504 response codes.
+	download := []string{
+		"curl", "--fail", "--location", "--silent", "--show-error",
+		"--retry", strconv.Itoa(int(retries)), "--retry-connrefused", "--retry-delay", "5",
+		"--output", archivePath,
+	}
+	pod := &populator_machinery.Pod{}
+	container := corev1.Container{
+		Name:  populator_machinery.ContainerName,
+		Image: imageName,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      archiveVolumeName,
+				MountPath: archiveMountPath,
+			},
+		},
+	}
+	pod.Spec.Volumes = []corev1.Volume{
+		{
+			Name: archiveVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	if populator.Spec.HeadersSecret != "" {
+		headers, err := secret.Get(kubeClient, populator.GetNamespace(), populator.Spec.HeadersSecret)
+		if err != nil {
+			return nil, err
+		}
+		// Sort the headers so that the file is the same for every call
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines := &strings.Builder{}
+		for _, name := range names {
+			if strings.ContainsAny(name+headers[name], "\r\n") {
+				return nil, fmt.Errorf("invalid header `%s` in `%s` secret of %s `%s`",
+					name, populator.Spec.HeadersSecret, kind, populator.GetName())
+			}
+			lines.WriteString(name + ": " + headers[name] + "\n")
+		}
+		// The headers are passed in a file so that they are not in the
+		// spec of the pod
+		pod.SecretData = map[string][]byte{headersKey: []byte(lines.String())}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: headersVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: populator_machinery.SecretName},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      headersVolumeName,
+			MountPath: headersMountPath,
+			ReadOnly:  true,
+		})
+		download = append(download, "--header", "@"+headersMountPath+"/"+headersKey)
+	}
+	download = append(download, populator.Spec.URL)
+
+	script := &shell.Script{}
+	script.Run(download...).
+		Raw("echo " + shell.Quote(strings.ToLower(populator.Spec.SHA256)+"  "+archivePath) + " | sha256sum -c -")
+	switch format {
+	case formatTar:
+		script.Run("tar", "-x", "-f", archivePath, "-C", mountPath)
+	case formatTarGz:
+		script.Run("tar", "-x", "-z", "-f", archivePath, "-C", mountPath)
+	case formatTarZst:
+		script.Run("tar", "-x", "--zstd", "-f", archivePath, "-C", mountPath)
+	case formatZip:
+		script.Run("unzip", "-o", "-q", archivePath, "-d", mountPath)
+	}
+
+	container.Args = script.Args()
+	pod.Spec.Containers = []corev1.Container{container}
+	return pod, nil
+}
+
+// getFormat returns the format of the archive. If the format is
+// not set it is detected using the extension of the url path.
+func getFormat(spec internalv1alpha1.HTTPPopulatorSpec) (string, error) {
+	if spec.Format != "" {
+		for _, f := range formatSuffixes {
+			if f == spec.Format {
+				return spec.Format, nil
+			}
+		}
+		return "", fmt.Errorf("unsupported format `%s`", spec.Format)
+	}
+
+	u, err := url.Parse(spec.URL)
+	if err != nil {
+		return "", err
+	}
+	path := strings.ToLower(u.Path)
+	// Check the longest suffix first so that .tar.gz is not taken as .gz
+	suffixes := make([]string, 0, len(formatSuffixes))
+	for s := range formatSuffixes {
+		suffixes = append(suffixes, s)
+	}
+	sort.Slice(suffixes, func(i, j int) bool { return len(suffixes[i]) > len(suffixes[j]) })
+	for _, s := range suffixes {
+		if strings.HasSuffix(path, s) {
+			return formatSuffixes[s], nil
+		}
+	}
+	return "", fmt.Errorf("format can not be detected from `%s`, please set the format", spec.URL)
+}
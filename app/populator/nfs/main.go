@@ -0,0 +1,132 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	"github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/rsync"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	prefix     = "openebs.io"
+	mountPath  = "/mnt"
+	devicePath = "/dev/block"
+
+	groupName  = "openebs.io"
+	apiVersion = "v1alpha1"
+	kind       = "NFSPopulator"
+	resource   = "nfspopulators"
+
+	// sourceVolumeName is the name of the nfs volume in the populator pod
+	sourceVolumeName = "source"
+	// sourceMountPath is where the export is mounted in the populator pod
+	sourceMountPath = "/data"
+)
+
+var (
+	gk  = schema.GroupKind{Group: groupName, Kind: kind}
+	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	imageName string
+)
+
+func main() {
+	klog.InitFlags(nil)
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		panic(err)
+	}
+
+	flag.StringVar(&imageName, "image-name", "", "Image to use for populating")
+	flag.Parse()
+
+	namespace := os.Getenv("POD_NAMESPACE")
+
+	populator.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
+}
+
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator.Pod, error) {
+	if rawBlock {
+		return nil, fmt.Errorf("block volumes are not supported by %s", kind)
+	}
+
+	nfsPopulator := internalv1alpha1.NFSPopulator{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(u.UnstructuredContent(), &nfsPopulator)
+	if err != nil {
+		return nil, err
+	}
+	spec := nfsPopulator.Spec
+
+	if spec.Server == "" || !path.IsAbs(spec.Path) {
+		return nil, fmt.Errorf("invalid export `%s:%s` in %s `%s`, path must be absolute",
+			spec.Server, spec.Path, kind, nfsPopulator.GetName())
+	}
+	subPath, err := rsync.CleanSubPath(spec.SubPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subPath in %s `%s` error: %s", kind, nfsPopulator.GetName(), err)
+	}
+
+	// The trailing slash copies the contents of the export, the same
+	// as the rsync populator does.
+	script := &shell.Script{}
+	cmd := append([]string{"rsync", "--verbose"}, rsync.Args(spec.Options)...)
+	script.Run(append(cmd, sourceMountPath+"/", mountPath)...)
+
+	return &populator.Pod{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  populator.ContainerName,
+				Image: imageName,
+				Args:  script.Args(),
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      sourceVolumeName,
+						MountPath: sourceMountPath,
+						SubPath:   subPath,
+						ReadOnly:  true,
+					},
+				},
+			},
+		},
+		Volumes: []corev1.Volume{
+			{
+				Name: sourceVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					NFS: &corev1.NFSVolumeSource{
+						Server:   spec.Server,
+						Path:     spec.Path,
+						ReadOnly: true,
+					},
+				},
+			},
+		},
+	}}, nil
+}
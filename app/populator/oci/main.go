@@ -0,0 +1,170 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	populator_machinery "github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/secret"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	prefix     = "openebs.io"
+	mountPath  = "/mnt"
+	devicePath = "/dev/block"
+
+	groupName  = "openebs.io"
+	apiVersion = "v1alpha1"
+	kind       = "OCIPopulator"
+	resource   = "ocipopulators"
+
+	// populateScript is shipped in the oci-client image, it pulls
+	// and extracts the image or artifact.
+	populateScript = "oci-populate.sh"
+
+	// The registry credentials are mounted as the config.json of the
+	// docker config directory from the secret of the pod.
+	dockerConfigVolumeName = "docker-config"
+	dockerConfigMountPath  = "/etc/oci-populator/docker"
+	dockerConfigFile       = "config.json"
+)
+
+var (
+	gk  = schema.GroupKind{Group: groupName, Kind: kind}
+	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	kubeClient kubernetes.Interface
+
+	imageName string
+
+	digestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+func main() {
+	klog.InitFlags(nil)
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		panic(err)
+	}
+
+	flag.StringVar(&imageName, "image-name", "", "Image to use for populating")
+	flag.Parse()
+
+	namespace := os.Getenv("POD_NAMESPACE")
+
+	// The client is used to read the credentials secret of the populators
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+	kubeClient, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	populator_machinery.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
+}
+
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator_machinery.Pod, error) {
+	if rawBlock {
+		return nil, fmt.Errorf("block volumes are not supported by %s", kind)
+	}
+
+	populator := internalv1alpha1.OCIPopulator{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(u.UnstructuredContent(), &populator)
+	if err != nil {
+		return nil, err
+	}
+	spec := populator.Spec
+
+	if spec.Repository == "" || strings.ContainsAny(spec.Repository, "@") {
+		return nil, fmt.Errorf("invalid repository `%s` in %s `%s`, it must not have a digest",
+			spec.Repository, kind, populator.GetName())
+	}
+	for _, d := range append([]string{spec.Digest}, spec.Layers...) {
+		if !digestRegex.MatchString(d) {
+			return nil, fmt.Errorf("invalid digest `%s` in %s `%s`", d, kind, populator.GetName())
+		}
+	}
+
+	pod := &populator_machinery.Pod{}
+	container := corev1.Container{
+		Name:  populator_machinery.ContainerName,
+		Image: imageName,
+	}
+	if spec.CredentialsSecret != "" {
+		creds, err := secret.Get(kubeClient, populator.GetNamespace(), spec.CredentialsSecret,
+			corev1.DockerConfigJsonKey)
+		if err != nil {
+			return nil, err
+		}
+		pod.SecretData = map[string][]byte{dockerConfigFile: []byte(creds[corev1.DockerConfigJsonKey])}
+		pod.Spec.Volumes = []corev1.Volume{
+			{
+				Name: dockerConfigVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: populator_machinery.SecretName},
+				},
+			},
+		}
+		container.VolumeMounts = []corev1.VolumeMount{
+			{
+				Name:      dockerConfigVolumeName,
+				MountPath: dockerConfigMountPath,
+				ReadOnly:  true,
+			},
+		}
+		container.Env = []corev1.EnvVar{{Name: "DOCKER_CONFIG", Value: dockerConfigMountPath}}
+	}
+
+	cmd := []string{populateScript,
+		"--repository", spec.Repository,
+		"--digest", spec.Digest,
+		"--target", mountPath,
+	}
+	if spec.Insecure {
+		cmd = append(cmd, "--insecure")
+	}
+	for _, l := range spec.Layers {
+		cmd = append(cmd, "--layer", l)
+	}
+	for _, p := range spec.Paths {
+		cmd = append(cmd, "--path", strings.TrimPrefix(p, "/"))
+	}
+	script := &shell.Script{}
+	script.Run(cmd...)
+
+	container.Args = script.Args()
+	pod.Spec.Containers = []corev1.Container{container}
+	return pod, nil
+}
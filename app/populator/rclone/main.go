@@ -0,0 +1,181 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	populator_machinery "github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/rclone"
+	"github.com/openebs/data-populator/pkg/secret"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	prefix     = "openebs.io"
+	mountPath  = "/mnt"
+	devicePath = "/dev/block"
+
+	groupName  = "openebs.io"
+	apiVersion = "v1alpha1"
+	kind       = "RclonePopulator"
+	resource   = "rclonepopulators"
+
+	// remoteName is the name of the rclone remote configured
+	// through the environment variables.
+	remoteName      = "SRC"
+	defaultProvider = "Other"
+
+	accessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	secretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+)
+
+var (
+	gk  = schema.GroupKind{Group: groupName, Kind: kind}
+	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	kubeClient kubernetes.Interface
+
+	imageName string
+)
+
+func main() {
+	klog.InitFlags(nil)
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		panic(err)
+	}
+
+	flag.StringVar(&imageName, "image-name", "", "Image to use for populating")
+	flag.Parse()
+
+	namespace := os.Getenv("POD_NAMESPACE")
+
+	// The client is used to read the credentials secret of the populators
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+	kubeClient, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	populator_machinery.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
+}
+
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator_machinery.Pod, error) {
+	if rawBlock {
+		return nil, fmt.Errorf("block volumes are not supported by %s", kind)
+	}
+
+	populator := internalv1alpha1.RclonePopulator{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(u.UnstructuredContent(), &populator)
+	if err != nil {
+		return nil, err
+	}
+	if populator.Spec.Bucket == "" {
+		return nil, fmt.Errorf("bucket is not set in %s `%s`", kind, populator.GetName())
+	}
+
+	provider := populator.Spec.Provider
+	if provider == "" {
+		provider = defaultProvider
+	}
+
+	// The remote is configured using the RCLONE_CONFIG_<remote>_<option>
+	// environment variables so no config file is needed.
+	env := []corev1.EnvVar{
+		{Name: remoteEnv("TYPE"), Value: "s3"},
+		{Name: remoteEnv("PROVIDER"), Value: provider},
+	}
+	if populator.Spec.Endpoint != "" {
+		env = append(env, corev1.EnvVar{Name: remoteEnv("ENDPOINT"), Value: populator.Spec.Endpoint})
+	}
+	if populator.Spec.Region != "" {
+		env = append(env, corev1.EnvVar{Name: remoteEnv("REGION"), Value: populator.Spec.Region})
+	}
+	pod := &populator_machinery.Pod{}
+	if populator.Spec.CredentialsSecret != "" {
+		creds, err := secret.Get(kubeClient, populator.GetNamespace(), populator.Spec.CredentialsSecret,
+			accessKeyIDKey, secretAccessKeyKey)
+		if err != nil {
+			return nil, err
+		}
+		// The keys are read from the secret of the pod, so that they are
+		// not in its spec
+		pod.SecretData = map[string][]byte{
+			accessKeyIDKey:     []byte(creds[accessKeyIDKey]),
+			secretAccessKeyKey: []byte(creds[secretAccessKeyKey]),
+		}
+		env = append(env, secretEnv(remoteEnv("ACCESS_KEY_ID"), accessKeyIDKey),
+			secretEnv(remoteEnv("SECRET_ACCESS_KEY"), secretAccessKeyKey))
+	} else {
+		env = append(env, corev1.EnvVar{Name: remoteEnv("ENV_AUTH"), Value: "true"})
+	}
+
+	source := remoteName + ":" + populator.Spec.Bucket
+	if keyPrefix := strings.Trim(populator.Spec.Prefix, "/"); keyPrefix != "" {
+		source += "/" + keyPrefix
+	}
+	cmd := []string{"rclone", "copy", source, mountPath, "--verbose", "--stats", "30s"}
+	cmd = append(cmd, rclone.FilterArgs(populator.Spec.Include, populator.Spec.Exclude)...)
+	script := &shell.Script{}
+	script.Run(cmd...)
+
+	pod.Spec = corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  populator_machinery.ContainerName,
+				Image: imageName,
+				Args:  script.Args(),
+				Env:   env,
+			},
+		},
+	}
+	return pod, nil
+}
+
+func remoteEnv(option string) string {
+	return "RCLONE_CONFIG_" + remoteName + "_" + option
+}
+
+// secretEnv returns the environment variable set from the key of the secret of the pod
+func secretEnv(name, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: populator_machinery.SecretName},
+				Key:                  key,
+			},
+		},
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	populator_machinery "github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/secret"
+	"github.com/openebs/data-populator/pkg/shell"
+)
+
+const (
+	prefix     = "openebs.io"
+	mountPath  = "/mnt"
+	devicePath = "/dev/block"
+
+	groupName  = "openebs.io"
+	apiVersion = "v1alpha1"
+	kind       = "RestorePopulator"
+	resource   = "restorepopulators"
+
+	// restoreScript is shipped in the restore-client image, it restores
+	// the snapshot using restic or kopia.
+	restoreScript = "restore.sh"
+
+	passwordKey = "password"
+	tokenKey    = "token"
+)
+
+var (
+	gk  = schema.GroupKind{Group: groupName, Kind: kind}
+	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	kubeClient kubernetes.Interface
+
+	imageName string
+
+	envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+func main() {
+	klog.InitFlags(nil)
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		panic(err)
+	}
+
+	flag.StringVar(&imageName, "image-name", "", "Image to use for populating")
+	flag.Parse()
+
+	namespace := os.Getenv("POD_NAMESPACE")
+
+	// The client is used to read the password and credentials secrets of the populators
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+	kubeClient, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	populator_machinery.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
+}
+
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator_machinery.Pod, error) {
+	if rawBlock {
+		return nil, fmt.Errorf("block volumes are not supported by %s", kind)
+	}
+
+	populator := internalv1alpha1.RestorePopulator{}
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(u.UnstructuredContent(), &populator)
+	if err != nil {
+		return nil, err
+	}
+	spec := populator.Spec
+
+	engine := spec.Engine
+	if engine == "" {
+		engine = internalv1alpha1.RestoreEngineRestic
+	}
+	if engine != internalv1alpha1.RestoreEngineRestic && engine != internalv1alpha1.RestoreEngineKopia {
+		return nil, fmt.Errorf("unsupported engine `%s` in %s `%s`", engine, kind, populator.GetName())
+	}
+
+	if spec.PasswordSecret == "" {
+		return nil, fmt.Errorf("passwordSecret is required in %s `%s`", kind, populator.GetName())
+	}
+	password, err := secret.Get(kubeClient, populator.GetNamespace(), spec.PasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	_, hasPassword := password[passwordKey]
+	token, hasToken := password[tokenKey]
+	if engine == internalv1alpha1.RestoreEngineKopia && hasToken {
+		// The token has the password too, unless it was created without one
+		hasPassword = true
+	}
+	if !hasPassword {
+		return nil, fmt.Errorf("secret `%s` in `%s` namespace does not have the `%s` key",
+			spec.PasswordSecret, populator.GetNamespace(), passwordKey)
+	}
+	if spec.Repository == "" && !(engine == internalv1alpha1.RestoreEngineKopia && hasToken) {
+		return nil, fmt.Errorf("repository is required in %s `%s`", kind, populator.GetName())
+	}
+
+	// Every key of the secret of the pod is set in the environment of
+	// the restore script, so that the credentials are not in the spec
+	// of the pod.
+	env := map[string][]byte{}
+	if spec.CredentialsSecret != "" {
+		creds, err := secret.Get(kubeClient, populator.GetNamespace(), spec.CredentialsSecret)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range creds {
+			if !envNameRegex.MatchString(name) {
+				return nil, fmt.Errorf("key `%s` of secret `%s` is not a valid environment variable name",
+					name, spec.CredentialsSecret)
+			}
+			env[name] = []byte(value)
+		}
+	}
+	env["RESTORE_PASSWORD"] = []byte(password[passwordKey])
+	if engine == internalv1alpha1.RestoreEngineKopia && hasToken {
+		env["KOPIA_TOKEN"] = []byte(token)
+	}
+
+	cmd := []string{restoreScript,
+		"--engine", engine,
+		"--target", mountPath,
+	}
+	if spec.Repository != "" {
+		cmd = append(cmd, "--repository", spec.Repository)
+	}
+	if spec.SnapshotID != "" {
+		cmd = append(cmd, "--snapshot", spec.SnapshotID)
+	}
+	for _, t := range spec.Tags {
+		cmd = append(cmd, "--tag", t)
+	}
+	if spec.Host != "" {
+		cmd = append(cmd, "--host", spec.Host)
+	}
+	if spec.Path != "" {
+		cmd = append(cmd, "--path", spec.Path)
+	}
+	script := &shell.Script{}
+	script.Run(cmd...)
+
+	return &populator_machinery.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  populator_machinery.ContainerName,
+					Image: imageName,
+					Args:  script.Args(),
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							SecretRef: &corev1.SecretEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: populator_machinery.SecretName},
+							},
+						},
+					},
+				},
+			},
+		},
+		SecretData: env,
+	}, nil
+}
@@ -18,15 +18,25 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"path"
+	"strconv"
+	"strings"
 
-	populator_machinery "github.com/kubernetes-csi/lib-volume-populator/populator-machinery"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
 
 	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+	populator_machinery "github.com/openebs/data-populator/pkg/populator"
+	"github.com/openebs/data-populator/pkg/rsync"
+	"github.com/openebs/data-populator/pkg/secret"
+	"github.com/openebs/data-populator/pkg/shell"
 )
 
 const (
@@ -38,11 +48,40 @@ const (
 	apiVersion = "v1alpha1"
 	kind       = "RsyncPopulator"
 	resource   = "rsyncpopulators"
+
+	// keys of the connection secret
+	urlKey      = "url"
+	pathKey     = "path"
+	usernameKey = "username"
+	passwordKey = "password"
+	caKey       = "ca.crt"
+
+	defaultRsyncPort = "873"
+
+	// The password and the ca certificate are passed to the containers
+	// through the secret of the populator pod, so that they are not in
+	// the spec of the pod.
+	credentialsVolumeName = "credentials"
+	credentialsMountPath  = "/etc/rsync-populator"
+	caFile                = credentialsMountPath + "/" + caKey
+
+	// sourceContainerPrefix is the prefix of the names of the containers
+	// copying the sources, they are followed by the index of the source
+	sourceContainerPrefix = "source-"
+	// checkpointDir is the directory in the volume being populated where a
+	// file is kept for every source which is copied, so that a populator pod
+	// which is run again copies only the rest of the sources. It is removed
+	// once all of the sources are copied.
+	checkpointDir = mountPath + "/.rsync-populator"
 )
 
 var (
 	gk  = schema.GroupKind{Group: groupName, Kind: kind}
 	gvr = schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resource}
+
+	kubeClient kubernetes.Interface
+
+	imageName string
 )
 
 func main() {
@@ -51,19 +90,26 @@ func main() {
 		panic(err)
 	}
 
-	var (
-		imageName string
-	)
 	flag.StringVar(&imageName, "image-name", "", "Image to use for populating")
 	flag.Parse()
 
 	namespace := os.Getenv("POD_NAMESPACE")
 
-	populator_machinery.RunController("", "", imageName,
-		namespace, prefix, gk, gvr, mountPath, devicePath, getPopulatorArgs)
+	// The client is used to read the connection secret of the populators
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+	kubeClient, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	populator_machinery.RunController("", "", namespace, prefix, gk, gvr,
+		mountPath, devicePath, getPopulatorPod)
 }
 
-func getPopulatorArgs(rawBlock bool, u *unstructured.Unstructured) ([]string, error) {
+func getPopulatorPod(rawBlock bool, u *unstructured.Unstructured) (*populator_machinery.Pod, error) {
 	populator := internalv1alpha1.RsyncPopulator{}
 	err := runtime.DefaultUnstructuredConverter.
 		FromUnstructured(u.UnstructuredContent(), &populator)
@@ -71,10 +117,168 @@ func getPopulatorArgs(rawBlock bool, u *unstructured.Unstructured) ([]string, er
 		return nil, err
 	}
 
-	args := []string{
-		"bash",
-		"-c",
-		"export RSYNC_PASSWORD=" + populator.Spec.Password + "; rsync -rv rsync://" + populator.Spec.Username + "@" + populator.Spec.URL + populator.Spec.Path + " " + mountPath,
+	spec := populator.Spec
+	var ca string
+	if spec.ConnectionSecret != "" {
+		conn, err := secret.Get(kubeClient, populator.GetNamespace(), spec.ConnectionSecret)
+		if err != nil {
+			return nil, err
+		}
+		for key, field := range map[string]*string{
+			urlKey:      &spec.URL,
+			pathKey:     &spec.Path,
+			usernameKey: &spec.Username,
+			passwordKey: &spec.Password,
+			caKey:       &ca,
+		} {
+			if v, ok := conn[key]; ok {
+				*field = v
+			}
+		}
+	}
+	sources := spec.Sources
+	if len(sources) == 0 {
+		if spec.URL == "" {
+			return nil, fmt.Errorf("url is not set in %s `%s`", kind, populator.GetName())
+		}
+		sources = []internalv1alpha1.RsyncPopulatorSource{{URL: spec.URL, Path: spec.Path}}
+	}
+	subPath, err := rsync.CleanSubPath(spec.SubPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subPath in %s `%s`: %s", kind, populator.GetName(), err)
+	}
+
+	podSpec := corev1.PodSpec{}
+	secretData := map[string][]byte{}
+	var env []corev1.EnvVar
+	var mounts []corev1.VolumeMount
+	if spec.Password != "" {
+		secretData[passwordKey] = []byte(spec.Password)
+		env = append(env, corev1.EnvVar{
+			Name: "RSYNC_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: populator_machinery.SecretName},
+					Key:                  passwordKey,
+				},
+			},
+		})
 	}
-	return args, nil
+	if ca != "" {
+		secretData[caKey] = []byte(ca)
+		podSpec.Volumes = []corev1.Volume{
+			{
+				Name: credentialsVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: populator_machinery.SecretName,
+						Items:      []corev1.KeyToPath{{Key: caKey, Path: caKey}},
+					},
+				},
+			},
+		}
+		mounts = []corev1.VolumeMount{
+			{
+				Name:      credentialsVolumeName,
+				MountPath: credentialsMountPath,
+				ReadOnly:  true,
+			},
+		}
+	}
+
+	containers := make([]corev1.Container, 0, len(sources))
+	for i, source := range sources {
+		if source.URL == "" {
+			return nil, fmt.Errorf("url of source %d is not set in %s `%s`", i, kind, populator.GetName())
+		}
+		sourceSubPath, err := rsync.CleanSubPath(source.SubPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subPath of source %d in %s `%s`: %s", i, kind, populator.GetName(), err)
+		}
+		destination := mountPath
+		for _, p := range []string{subPath, sourceSubPath} {
+			if p != "" {
+				destination += "/" + p
+			}
+		}
+		checkpoint := ""
+		if len(spec.Sources) > 0 {
+			checkpoint = checkpointDir + "/" + sourceContainerPrefix + strconv.Itoa(i)
+		}
+		containers = append(containers, corev1.Container{
+			Name:         sourceContainerPrefix + strconv.Itoa(i),
+			Image:        imageName,
+			Args:         getRsyncArgs(spec, ca != "", source, destination, checkpoint),
+			Env:          env,
+			VolumeMounts: mounts,
+		})
+	}
+	pod := &populator_machinery.Pod{SecretData: secretData}
+	if len(spec.Sources) == 0 {
+		containers[0].Name = populator_machinery.ContainerName
+		podSpec.Containers = containers
+		pod.Spec = podSpec
+		return pod, nil
+	}
+
+	// The sources are copied one after the other by the init containers,
+	// the progress of copying each of them is in the status of its container
+	podSpec.InitContainers = containers
+	podSpec.Containers = []corev1.Container{
+		{
+			Name:  populator_machinery.ContainerName,
+			Image: imageName,
+			Args:  (&shell.Script{}).Run("rm", "-rf", checkpointDir).Run("sync").Args(),
+		},
+	}
+	pod.Spec = podSpec
+	return pod, nil
+}
+
+// getRsyncArgs returns the args of the container copying the data of the
+// source into the destination. If the checkpoint is set, the source is not
+// copied again once the checkpoint file is created after copying it. The
+// certificate of the daemon is verified with the mounted ca if hasCA is set.
+func getRsyncArgs(spec internalv1alpha1.RsyncPopulatorSpec, hasCA bool,
+	source internalv1alpha1.RsyncPopulatorSource, destination, checkpoint string) []string {
+	script := &shell.Script{}
+	if checkpoint != "" {
+		script.Raw("if [ -f " + shell.Quote(checkpoint) + " ]; then exit 0; fi")
+	}
+	if hasCA {
+		// rsync runs the connect program instead of connecting to the
+		// daemon, openssl verifies the certificate of the daemon.
+		address := source.URL
+		if !strings.Contains(address, ":") {
+			address += ":" + defaultRsyncPort
+		}
+		script.Export("RSYNC_CONNECT_PROG", "openssl s_client -quiet -verify_return_error -CAfile "+
+			caFile+" -connect "+shell.Quote(address))
+	}
+	// The times of the copied files are kept and a partially copied file is
+	// kept in the partial dir, so that a populator pod which is run again
+	// copies only the rest of the data. The partial file is used as the basis
+	// of the delta transfer, so a file which changed in the source since then
+	// is still copied correctly. rsync excludes the relative partial dir from
+	// the transfer and from the deletion.
+	args := append([]string{"rsync", "-v"}, rsync.Args(spec.Options)...)
+	args = append(args, "--partial", "--partial-dir=.rsync-partial")
+	if spec.BandwidthLimit != nil {
+		// rsync takes the limit in KiB per second
+		args = append(args, fmt.Sprintf("--bwlimit=%d", (spec.BandwidthLimit.Value()+1023)/1024))
+	}
+	if checkpoint != "" && destination == mountPath {
+		// The checkpoints are not deleted by --delete
+		args = append(args, "--filter=P /"+path.Base(checkpointDir)+"/")
+	}
+	args = append(args, rsync.FilterArgs(spec.Include, spec.Exclude)...)
+	if destination != mountPath {
+		script.Run("mkdir", "-p", destination)
+	}
+	script.Run(append(args, "rsync://"+spec.Username+"@"+source.URL+source.Path, destination)...)
+	if checkpoint != "" {
+		script.Run("mkdir", "-p", checkpointDir).Run("touch", checkpoint)
+	}
+
+	return script.Args()
 }
@@ -64,10 +64,231 @@ cat deploy/crds/openebs.io_rsyncpopulators.yaml
 } > deploy/crds/rsyncpopulator-crd.yaml
 rm deploy/crds/openebs.io_rsyncpopulators.yaml
 
+{
+echo "
+
+###############################################
+###########                        ############
+###########   RclonePopulator CRD  ############
+###########                        ############
+###############################################
+
+# RclonePopulator CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_rclonepopulators.yaml
+} > deploy/crds/rclonepopulator-crd.yaml
+rm deploy/crds/openebs.io_rclonepopulators.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   HTTPPopulator CRD    ############
+###########                        ############
+###############################################
+
+# HTTPPopulator CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_httppopulators.yaml
+} > deploy/crds/httppopulator-crd.yaml
+rm deploy/crds/openebs.io_httppopulators.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   GitPopulator CRD     ############
+###########                        ############
+###############################################
+
+# GitPopulator CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_gitpopulators.yaml
+} > deploy/crds/gitpopulator-crd.yaml
+rm deploy/crds/openebs.io_gitpopulators.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   OCIPopulator CRD     ############
+###########                        ############
+###############################################
+
+# OCIPopulator CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_ocipopulators.yaml
+} > deploy/crds/ocipopulator-crd.yaml
+rm deploy/crds/openebs.io_ocipopulators.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   RestorePopulator CRD ############
+###########                        ############
+###############################################
+
+# RestorePopulator CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_restorepopulators.yaml
+} > deploy/crds/restorepopulator-crd.yaml
+rm deploy/crds/openebs.io_restorepopulators.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+########### ContainerPopulator CRD ############
+###########                        ############
+###############################################
+
+# ContainerPopulator CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_containerpopulators.yaml
+} > deploy/crds/containerpopulator-crd.yaml
+rm deploy/crds/openebs.io_containerpopulators.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   NFSPopulator CRD     ############
+###########                        ############
+###############################################
+
+# NFSPopulator CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_nfspopulators.yaml
+} > deploy/crds/nfspopulator-crd.yaml
+rm deploy/crds/openebs.io_nfspopulators.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   DataExport CRD       ############
+###########                        ############
+###############################################
+
+# DataExport CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_dataexports.yaml
+} > deploy/crds/dataexport-crd.yaml
+rm deploy/crds/openebs.io_dataexports.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   PVCExport CRD        ############
+###########                        ############
+###############################################
+
+# PVCExport CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_pvcexports.yaml
+} > deploy/crds/pvcexport-crd.yaml
+rm deploy/crds/openebs.io_pvcexports.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   DataSync CRD         ############
+###########                        ############
+###############################################
+
+# DataSync CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_datasyncs.yaml
+} > deploy/crds/datasync-crd.yaml
+rm deploy/crds/openebs.io_datasyncs.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   DataReplication CRD  ############
+###########                        ############
+###############################################
+
+# DataReplication CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_datareplications.yaml
+} > deploy/crds/datareplication-crd.yaml
+rm deploy/crds/openebs.io_datareplications.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########StatefulSetMigration CRD############
+###########                        ############
+###############################################
+
+# StatefulSetMigration CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_statefulsetmigrations.yaml
+} > deploy/crds/statefulsetmigration-crd.yaml
+rm deploy/crds/openebs.io_statefulsetmigrations.yaml
+
+{
+echo "
+
+###############################################
+###########                        ############
+###########   DataPopulatorSet CRD ############
+###########                        ############
+###############################################
+
+# DataPopulatorSet CRD is autogenerated via \`make manifests\` command.
+# Do the modification in the code and run the \`make manifests\` command
+# to generate the CRD definition"
+
+cat deploy/crds/openebs.io_datapopulatorsets.yaml
+} > deploy/crds/datapopulatorset-crd.yaml
+rm deploy/crds/openebs.io_datapopulatorsets.yaml
+
 ## create the operator file using all the yamls
 {
 echo "# This manifest is autogenerated via \`make manifests\` command
-# Do the modification to the data-populator.yaml or rsync-populator in
+# Do the modification to the populator yamls in the
 # directory deploy/yamls/ and then run \`make manifests\` command
 
 # This manifest deploys the data populator components,
@@ -80,11 +301,71 @@ cat deploy/crds/datapopulator-crd.yaml
 # Add rsync populator v1alpha1 CRDs to the Operator yaml
 cat deploy/crds/rsyncpopulator-crd.yaml
 
+# Add rclone populator v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/rclonepopulator-crd.yaml
+
+# Add http populator v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/httppopulator-crd.yaml
+
+# Add git populator v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/gitpopulator-crd.yaml
+
+# Add oci populator v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/ocipopulator-crd.yaml
+
+# Add restore populator v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/restorepopulator-crd.yaml
+
+# Add container populator v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/containerpopulator-crd.yaml
+
+# Add nfs populator v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/nfspopulator-crd.yaml
+
+# Add data export v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/dataexport-crd.yaml
+
+# Add pvc export v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/pvcexport-crd.yaml
+
+# Add data sync v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/datasync-crd.yaml
+
+# Add data replication v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/datareplication-crd.yaml
+
+# Add statefulset migration v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/statefulsetmigration-crd.yaml
+
+# Add data populator set v1alpha1 CRDs to the Operator yaml
+cat deploy/crds/datapopulatorset-crd.yaml
+
 # Add the data populator deployment to the Operator yaml
 cat deploy/yamls/data-populator.yaml
 
 # Add the rsync populator deployment to the Operator yaml
 cat deploy/yamls/rsync-populator.yaml
+
+# Add the rclone populator deployment to the Operator yaml
+cat deploy/yamls/rclone-populator.yaml
+
+# Add the http populator deployment to the Operator yaml
+cat deploy/yamls/http-populator.yaml
+
+# Add the git populator deployment to the Operator yaml
+cat deploy/yamls/git-populator.yaml
+
+# Add the oci populator deployment to the Operator yaml
+cat deploy/yamls/oci-populator.yaml
+
+# Add the restore populator deployment to the Operator yaml
+cat deploy/yamls/restore-populator.yaml
+
+# Add the container populator deployment to the Operator yaml
+cat deploy/yamls/container-populator.yaml
+
+# Add the nfs populator deployment to the Operator yaml
+cat deploy/yamls/nfs-populator.yaml
 } > deploy/data-populator-operator.yaml
 
 # To use your own boilerplate text use:
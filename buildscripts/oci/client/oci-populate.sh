@@ -0,0 +1,195 @@
+#!/bin/bash
+
+# Copyright © 2022 The OpenEBS Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Pulls an image or artifact by digest and extracts it into the target
+# directory.
+#
+# Usage: oci-populate.sh --repository <repo> --digest <digest> --target <dir>
+#          [--insecure] [--layer <digest>]... [--path <path>]...
+#
+# If DOCKER_CONFIG is set, the config.json in it is used as the registry
+# credentials.
+
+set -eo pipefail
+
+REPOSITORY=""
+DIGEST=""
+TARGET=""
+CRANE_ARGS=()
+LAYERS=()
+PATHS=()
+
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --repository) REPOSITORY="$2"; shift 2 ;;
+    --digest)     DIGEST="$2"; shift 2 ;;
+    --target)     TARGET="$2"; shift 2 ;;
+    --insecure)   CRANE_ARGS+=("--insecure"); shift ;;
+    --layer)      LAYERS+=("$2"); shift 2 ;;
+    --path)       PATHS+=("$2"); shift 2 ;;
+    *) echo "unknown argument $1" >&2; exit 1 ;;
+  esac
+done
+
+if [ -z "$REPOSITORY" ] || [ -z "$DIGEST" ] || [ -z "$TARGET" ]; then
+  echo "--repository, --digest and --target are required" >&2
+  exit 1
+fi
+
+REF="$REPOSITORY@$DIGEST"
+WORKDIR="$TARGET/.oci-populator"
+mkdir -p "$WORKDIR"
+MANIFEST="$WORKDIR/manifest.json"
+BLOB="$WORKDIR/blob"
+
+# Verify the manifest against the requested digest
+crane manifest "${CRANE_ARGS[@]}" "$REF" > "$MANIFEST"
+echo "${DIGEST#sha256:}  $MANIFEST" | sha256sum -c -
+
+if ! jq -e '.layers' "$MANIFEST" > /dev/null; then
+  echo "$DIGEST is not an image or artifact manifest, use the digest of a platform specific manifest" >&2
+  exit 1
+fi
+
+# extract extracts the tar stream from stdin into the target. If paths are
+# given, the layers not having some of the paths is not an error, missing
+# paths are checked once all the layers are extracted.
+extract() {
+  if [ ${#PATHS[@]} -eq 0 ]; then
+    tar -x "$@" -C "$TARGET"
+    return
+  fi
+  if ! tar -x "$@" -C "$TARGET" -- "${PATHS[@]}" 2> "$WORKDIR/tar.err"; then
+    if grep -v -e "Not found in archive" -e "Exiting with failure status" "$WORKDIR/tar.err" | grep -q .; then
+      cat "$WORKDIR/tar.err" >&2
+      return 1
+    fi
+  fi
+}
+
+# whiteout applies the whiteouts of an image layer, in the tar archive of the
+# given args, to the layers which are extracted before it. A `.wh.<name>`
+# file deletes <name> and a `.wh..wh..opq` file deletes everything in its
+# directory. The directories are resolved through the symlinks extracted
+# from the previous layers, and must be inside the target.
+whiteout() {
+  local root
+  root=$(realpath -m "$TARGET")
+  tar -t "$@" | while IFS= read -r entry; do
+    entry="${entry#./}"
+    name=$(basename "$entry")
+    case "$name" in
+      .wh.*) ;;
+      *) continue ;;
+    esac
+    dir=$(realpath -m "$TARGET/$(dirname "$entry")")
+    case "/$entry/" in
+      */../*) dir="" ;;
+    esac
+    case "$dir" in
+      "$root"|"$root"/*) ;;
+      *) echo "layer has an invalid whiteout $entry" >&2; return 1 ;;
+    esac
+    if [ "$name" = ".wh..wh..opq" ]; then
+      if [ -d "$dir" ]; then
+        find "$dir" -mindepth 1 -maxdepth 1 ! -path "$WORKDIR" -exec rm -rf {} +
+      fi
+    else
+      rm -rf "${dir:?}/${name#.wh.}"
+    fi
+  done
+}
+
+# extract_layer applies the whiteouts of an image layer, and extracts the
+# layer without them
+extract_layer() {
+  if [ "$IS_IMAGE" = true ]; then
+    whiteout "$@" || return 1
+    extract --exclude='.wh.*' "$@"
+    return
+  fi
+  extract "$@"
+}
+
+CONFIG_MEDIA_TYPE=$(jq -r '.config.mediaType' "$MANIFEST")
+case "$CONFIG_MEDIA_TYPE" in
+  application/vnd.oci.image.config.v1+json|application/vnd.docker.container.image.v1+json)
+    IS_IMAGE=true ;;
+  *)
+    IS_IMAGE=false ;;
+esac
+
+if [ "$IS_IMAGE" = true ] && [ ${#LAYERS[@]} -eq 0 ]; then
+  # crane takes care of the whiteouts while flattening the layers
+  echo "extracting the filesystem of image $REF"
+  crane export "${CRANE_ARGS[@]}" "$REF" - | extract -f -
+else
+  for layer in "${LAYERS[@]}"; do
+    if ! jq -e --arg d "$layer" '.layers[] | select(.digest == $d)' "$MANIFEST" > /dev/null; then
+      echo "layer $layer is not present in $REF" >&2
+      exit 1
+    fi
+  done
+
+  jq -r '.layers[] | [.digest, .mediaType, (.annotations["org.opencontainers.image.title"] // "")] | @tsv' \
+    "$MANIFEST" > "$WORKDIR/layers"
+  while IFS=$'\t' read -r digest mediatype title; do
+    if [ ${#LAYERS[@]} -gt 0 ] && [[ ! " ${LAYERS[*]} " =~ \ ${digest}\  ]]; then
+      continue
+    fi
+    echo "extracting layer $digest ($mediatype)"
+    crane blob "${CRANE_ARGS[@]}" "$REPOSITORY@$digest" > "$BLOB"
+    echo "${digest#sha256:}  $BLOB" | sha256sum -c -
+    case "$mediatype" in
+      *tar+gzip|*tar.gzip)
+        extract_layer -z -f "$BLOB" ;;
+      *tar+zstd|*tar.zstd)
+        extract_layer --zstd -f "$BLOB" ;;
+      *tar)
+        extract_layer -f "$BLOB" ;;
+      *)
+        # Artifact files are stored as is, with the file name in the title
+        if [ -z "$title" ]; then
+          echo "layer $digest is not a tar archive and has no title annotation" >&2
+          exit 1
+        fi
+        if [ ${#PATHS[@]} -gt 0 ] && [[ ! " ${PATHS[*]} " =~ \ ${title}\  ]]; then
+          continue
+        fi
+        # The title must not take the file out of the target, also through
+        # the symlinks extracted from the previous layers
+        DEST=$(realpath -m "$TARGET/$title")
+        case "$title" in
+          /*|..|../*|*/..|*/../*) DEST="" ;;
+        esac
+        case "$DEST" in
+          "$(realpath -m "$TARGET")"/*) ;;
+          *) echo "layer $digest has an invalid title $title" >&2; exit 1 ;;
+        esac
+        mkdir -p "$(dirname "$DEST")"
+        mv "$BLOB" "$DEST" ;;
+    esac
+  done < "$WORKDIR/layers"
+fi
+
+rm -rf "$WORKDIR"
+
+for path in "${PATHS[@]}"; do
+  if [ ! -e "$TARGET/$path" ]; then
+    echo "path $path is not present in $REF" >&2
+    exit 1
+  fi
+done
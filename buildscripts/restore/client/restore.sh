@@ -0,0 +1,150 @@
+#!/bin/bash
+
+# Copyright © 2022 The OpenEBS Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Restores a snapshot from a restic or kopia repository into the target
+# directory.
+#
+# Usage: restore.sh --engine <restic|kopia> --target <dir> [--repository <repo>]
+#          [--snapshot <id>] [--tag <tag>]... [--host <host>] [--path <path>]
+#
+# RESTORE_PASSWORD must be set to the repository password. For kopia,
+# KOPIA_TOKEN can be set to connect to the repository using a token.
+
+set -eo pipefail
+
+ENGINE=""
+TARGET=""
+REPOSITORY=""
+SNAPSHOT=""
+TAGS=()
+HOST=""
+SOURCE_PATH=""
+
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --engine)     ENGINE="$2"; shift 2 ;;
+    --target)     TARGET="$2"; shift 2 ;;
+    --repository) REPOSITORY="$2"; shift 2 ;;
+    --snapshot)   SNAPSHOT="$2"; shift 2 ;;
+    --tag)        TAGS+=("$2"); shift 2 ;;
+    --host)       HOST="$2"; shift 2 ;;
+    --path)       SOURCE_PATH="$2"; shift 2 ;;
+    *) echo "unknown argument $1" >&2; exit 1 ;;
+  esac
+done
+
+if [ -z "$TARGET" ]; then
+  echo "--target is required" >&2
+  exit 1
+fi
+
+restic_restore() {
+  export RESTIC_REPOSITORY="$REPOSITORY"
+  export RESTIC_PASSWORD="$RESTORE_PASSWORD"
+  export RESTIC_CACHE_DIR=/tmp/restic-cache
+
+  local snapshot="${SNAPSHOT:-latest}"
+  local args=(--target "$TARGET")
+  # The filters are only used to find the latest snapshot
+  if [ -z "$SNAPSHOT" ]; then
+    if [ ${#TAGS[@]} -gt 0 ]; then
+      # Tags joined with a comma must all be present on the snapshot
+      args+=(--tag "$(IFS=,; echo "${TAGS[*]}")")
+    fi
+    if [ -n "$HOST" ]; then
+      args+=(--host "$HOST")
+    fi
+    if [ -n "$SOURCE_PATH" ]; then
+      args+=(--path "$SOURCE_PATH")
+    fi
+  fi
+  if [ -n "$SOURCE_PATH" ]; then
+    snapshot="$snapshot:$SOURCE_PATH"
+  fi
+
+  echo "restoring restic snapshot $snapshot"
+  restic restore "$snapshot" "${args[@]}"
+}
+
+kopia_connect() {
+  if [ -n "$KOPIA_TOKEN" ]; then
+    kopia repository connect from-config --token "$KOPIA_TOKEN"
+    return
+  fi
+
+  case "$REPOSITORY" in
+    s3:*)
+      local rest="${REPOSITORY#s3:}"
+      local args=()
+      case "$rest" in
+        http://*)  args+=(--disable-tls); rest="${rest#http://}" ;;
+        https://*) rest="${rest#https://}" ;;
+      esac
+      local endpoint="${rest%%/*}"
+      local path="${rest#*/}"
+      local bucket="${path%%/*}"
+      local prefix="${path#"$bucket"}"
+      prefix="${prefix#/}"
+      args+=(--endpoint "$endpoint" --bucket "$bucket")
+      if [ -n "$prefix" ]; then
+        args+=(--prefix "${prefix%/}/")
+      fi
+      # The access keys are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+      kopia repository connect s3 "${args[@]}"
+      ;;
+    *)
+      echo "unsupported kopia repository $REPOSITORY, use a connection token instead" >&2
+      exit 1
+      ;;
+  esac
+}
+
+kopia_restore() {
+  # A connection token can have the password too
+  if [ -n "$RESTORE_PASSWORD" ]; then
+    export KOPIA_PASSWORD="$RESTORE_PASSWORD"
+  fi
+  export KOPIA_CONFIG_PATH=/tmp/kopia/repository.config
+  export KOPIA_CACHE_DIRECTORY=/tmp/kopia/cache
+  export KOPIA_LOG_DIR=/tmp/kopia/logs
+  export KOPIA_CHECK_FOR_UPDATES=false
+
+  kopia_connect
+
+  local snapshot="$SNAPSHOT"
+  if [ -z "$snapshot" ]; then
+    local args=(--all --json)
+    for tag in "${TAGS[@]}"; do
+      args+=(--tags "$tag")
+    done
+    snapshot=$(kopia snapshot list "${args[@]}" | jq -r --arg host "$HOST" --arg path "$SOURCE_PATH" '
+      [.[] | select(($host == "" or .source.host == $host) and ($path == "" or .source.path == $path))]
+      | sort_by(.startTime) | last | .id // empty')
+    if [ -z "$snapshot" ]; then
+      echo "no kopia snapshot matches the given tags, host and path" >&2
+      exit 1
+    fi
+  fi
+
+  echo "restoring kopia snapshot $snapshot"
+  kopia snapshot restore "$snapshot" "$TARGET"
+}
+
+case "$ENGINE" in
+  restic) restic_restore ;;
+  kopia)  kopia_restore ;;
+  *) echo "unsupported engine $ENGINE" >&2; exit 1 ;;
+esac
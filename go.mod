@@ -3,7 +3,7 @@ module github.com/openebs/data-populator
 go 1.16
 
 require (
-	github.com/kubernetes-csi/lib-volume-populator v0.1.0
+	github.com/robfig/cron/v3 v3.0.1
 	k8s.io/api v0.22.0
 	k8s.io/apimachinery v0.22.0
 	k8s.io/client-go v0.22.0
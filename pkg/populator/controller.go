@@ -0,0 +1,849 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package populator is a fork of the populator machinery of
+// github.com/kubernetes-csi/lib-volume-populator v0.1.0. The machinery only
+// allows the args of the populator pod to be set, here the populator
+// returns the whole pod spec so that every data source can have its own
+// image, environment and volumes.
+package populator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/dynamiclister"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// ContainerName is the name to use for the container of the populator pod.
+const ContainerName = "populate"
+
+const (
+	populatorPodPrefix      = "populate"
+	populatorPodVolumeName  = "target"
+	populatorPvcPrefix      = "prime"
+	populatedFromAnnoSuffix = "populated-from"
+	pvcFinalizerSuffix      = "populate-target-protection"
+	annSelectedNode         = "volume.kubernetes.io/selected-node"
+)
+
+type empty struct{}
+
+type stringSet struct {
+	set map[string]empty
+}
+
+type controller struct {
+	populatorNamespace string
+	populatedFromAnno  string
+	pvcFinalizer       string
+	kubeClient         kubernetes.Interface
+	devicePath         string
+	mountPath          string
+	pvcLister          corelisters.PersistentVolumeClaimLister
+	pvcSynced          cache.InformerSynced
+	pvLister           corelisters.PersistentVolumeLister
+	pvSynced           cache.InformerSynced
+	podLister          corelisters.PodLister
+	podSynced          cache.InformerSynced
+	scLister           storagelisters.StorageClassLister
+	scSynced           cache.InformerSynced
+	unstLister         dynamiclister.Lister
+	unstSynced         cache.InformerSynced
+	mu                 sync.Mutex
+	notifyMap          map[string]*stringSet
+	cleanupMap         map[string]*stringSet
+	workqueue          workqueue.RateLimitingInterface
+	populatorPod       PodFunc
+	gk                 schema.GroupKind
+}
+
+// SecretName is the name by which the spec of a populator pod refers to the
+// secret created for it, in the env, envFrom and volumes of the pod. It is
+// replaced with the name of the secret when the pod is created.
+const SecretName = "populator-secret"
+
+// Pod is the pod populating the volume. If the secret data is set, it is
+// kept in a secret created for the pod in the namespace of the populator,
+// so that the credentials of a data source are not in the spec of the pod.
+type Pod struct {
+	Spec       corev1.PodSpec
+	SecretData map[string][]byte
+}
+
+// PodFunc returns the pod populating the volume from the given data
+// source. The volume being populated is added to the spec as the `target`
+// volume and is mounted into every container at the mount path, or
+// attached at the device path for block volumes.
+type PodFunc func(rawBlock bool, u *unstructured.Unstructured) (*Pod, error)
+
+// RunController runs the populator controller for the data sources of the
+// given kind until the process is signalled.
+func RunController(masterURL, kubeconfig, namespace, prefix string,
+	gk schema.GroupKind, gvr schema.GroupVersionResource, mountPath, devicePath string,
+	populatorPod PodFunc,
+) {
+	klog.Infof("Starting populator controller for %s", gk)
+
+	stopCh := make(chan struct{})
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		close(stopCh)
+		<-sigCh
+		os.Exit(1) // second signal. Exit directly.
+	}()
+
+	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
+	if nil != err {
+		klog.Fatalf("Failed to create config: %v", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if nil != err {
+		klog.Fatalf("Failed to create client: %v", err)
+	}
+
+	dynClient, err := dynamic.NewForConfig(cfg)
+	if nil != err {
+		klog.Fatalf("Failed to create dynamic client: %v", err)
+	}
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
+	dynInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynClient, time.Second*30)
+
+	pvcInformer := kubeInformerFactory.Core().V1().PersistentVolumeClaims()
+	pvInformer := kubeInformerFactory.Core().V1().PersistentVolumes()
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	scInformer := kubeInformerFactory.Storage().V1().StorageClasses()
+	unstInformer := dynInformerFactory.ForResource(gvr).Informer()
+
+	c := &controller{
+		kubeClient:         kubeClient,
+		populatorNamespace: namespace,
+		devicePath:         devicePath,
+		mountPath:          mountPath,
+		populatedFromAnno:  prefix + "/" + populatedFromAnnoSuffix,
+		pvcFinalizer:       prefix + "/" + pvcFinalizerSuffix,
+		pvcLister:          pvcInformer.Lister(),
+		pvcSynced:          pvcInformer.Informer().HasSynced,
+		pvLister:           pvInformer.Lister(),
+		pvSynced:           pvInformer.Informer().HasSynced,
+		podLister:          podInformer.Lister(),
+		podSynced:          podInformer.Informer().HasSynced,
+		scLister:           scInformer.Lister(),
+		scSynced:           scInformer.Informer().HasSynced,
+		unstLister:         dynamiclister.New(unstInformer.GetIndexer(), gvr),
+		unstSynced:         unstInformer.HasSynced,
+		notifyMap:          make(map[string]*stringSet),
+		cleanupMap:         make(map[string]*stringSet),
+		workqueue:          workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		populatorPod:       populatorPod,
+		gk:                 gk,
+	}
+
+	pvcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handlePVC,
+		UpdateFunc: func(old, new interface{}) {
+			newPvc := new.(*corev1.PersistentVolumeClaim)
+			oldPvc := old.(*corev1.PersistentVolumeClaim)
+			if newPvc.ResourceVersion == oldPvc.ResourceVersion {
+				return
+			}
+			c.handlePVC(new)
+		},
+		DeleteFunc: c.handlePVC,
+	})
+
+	pvInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handlePV,
+		UpdateFunc: func(old, new interface{}) {
+			newPv := new.(*corev1.PersistentVolume)
+			oldPv := old.(*corev1.PersistentVolume)
+			if newPv.ResourceVersion == oldPv.ResourceVersion {
+				return
+			}
+			c.handlePV(new)
+		},
+		DeleteFunc: c.handlePV,
+	})
+
+	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handlePod,
+		UpdateFunc: func(old, new interface{}) {
+			newPod := new.(*corev1.Pod)
+			oldPod := old.(*corev1.Pod)
+			if newPod.ResourceVersion == oldPod.ResourceVersion {
+				return
+			}
+			c.handlePod(new)
+		},
+		DeleteFunc: c.handlePod,
+	})
+
+	scInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleSC,
+		UpdateFunc: func(old, new interface{}) {
+			newSc := new.(*storagev1.StorageClass)
+			oldSc := old.(*storagev1.StorageClass)
+			if newSc.ResourceVersion == oldSc.ResourceVersion {
+				return
+			}
+			c.handleSC(new)
+		},
+		DeleteFunc: c.handleSC,
+	})
+
+	unstInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handleUnstructured,
+		UpdateFunc: func(old, new interface{}) {
+			newUnstructured := new.(*unstructured.Unstructured)
+			oldUnstructured := old.(*unstructured.Unstructured)
+			if newUnstructured.GetResourceVersion() == oldUnstructured.GetResourceVersion() {
+				return
+			}
+			c.handleUnstructured(new)
+		},
+		DeleteFunc: c.handleUnstructured,
+	})
+
+	kubeInformerFactory.Start(stopCh)
+	dynInformerFactory.Start(stopCh)
+
+	if err = c.run(stopCh); nil != err {
+		klog.Fatalf("Failed to run controller: %v", err)
+	}
+}
+
+func (c *controller) addNotification(keyToCall, objType, namespace, name string) {
+	var key string
+	if 0 == len(namespace) {
+		key = objType + "/" + name
+	} else {
+		key = objType + "/" + namespace + "/" + name
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.notifyMap[key]
+	if nil == s {
+		s = &stringSet{make(map[string]empty)}
+		c.notifyMap[key] = s
+	}
+	s.set[keyToCall] = empty{}
+	s = c.cleanupMap[keyToCall]
+	if nil == s {
+		s = &stringSet{make(map[string]empty)}
+		c.cleanupMap[keyToCall] = s
+	}
+	s.set[key] = empty{}
+}
+
+func (c *controller) cleanupNofications(keyToCall string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.cleanupMap[keyToCall]
+	if nil == s {
+		return
+	}
+	for key := range s.set {
+		t := c.notifyMap[key]
+		if nil == t {
+			continue
+		}
+		delete(t.set, keyToCall)
+		if 0 == len(t.set) {
+			delete(c.notifyMap, key)
+		}
+	}
+}
+
+func translateObject(obj interface{}) metav1.Object {
+	var object metav1.Object
+	var ok bool
+	if object, ok = obj.(metav1.Object); !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+			return nil
+		}
+		object, ok = tombstone.Obj.(metav1.Object)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+			return nil
+		}
+	}
+	return object
+}
+
+func (c *controller) handleMapped(obj interface{}, objType string) {
+	object := translateObject(obj)
+	if nil == object {
+		return
+	}
+	var key string
+	if 0 == len(object.GetNamespace()) {
+		key = objType + "/" + object.GetName()
+	} else {
+		key = objType + "/" + object.GetNamespace() + "/" + object.GetName()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.notifyMap[key]; ok {
+		for k := range s.set {
+			c.workqueue.Add(k)
+		}
+	}
+}
+
+func (c *controller) handlePVC(obj interface{}) {
+	c.handleMapped(obj, "pvc")
+	object := translateObject(obj)
+	if nil == object {
+		return
+	}
+	if c.populatorNamespace != object.GetNamespace() {
+		c.workqueue.Add("pvc/" + object.GetNamespace() + "/" + object.GetName())
+	}
+}
+
+func (c *controller) handlePV(obj interface{}) {
+	c.handleMapped(obj, "pv")
+}
+
+func (c *controller) handlePod(obj interface{}) {
+	c.handleMapped(obj, "pod")
+}
+
+func (c *controller) handleSC(obj interface{}) {
+	c.handleMapped(obj, "sc")
+}
+
+func (c *controller) handleUnstructured(obj interface{}) {
+	c.handleMapped(obj, "unstructured")
+}
+
+func (c *controller) run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	ok := cache.WaitForCacheSync(stopCh, c.pvcSynced, c.pvSynced, c.podSynced, c.scSynced, c.unstSynced)
+	if !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+
+	return nil
+}
+
+func (c *controller) runWorker() {
+	processNextWorkItem := func(obj interface{}) error {
+		defer c.workqueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.workqueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		var err error
+		parts := strings.Split(key, "/")
+		switch parts[0] {
+		case "pvc":
+			if 3 != len(parts) {
+				utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+				return nil
+			}
+			err = c.syncPvc(context.TODO(), key, parts[1], parts[2])
+		default:
+			utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+			return nil
+		}
+		if nil != err {
+			c.workqueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.workqueue.Forget(obj)
+		return nil
+	}
+
+	for {
+		obj, shutdown := c.workqueue.Get()
+		if shutdown {
+			return
+		}
+		err := processNextWorkItem(obj)
+		if nil != err {
+			utilruntime.HandleError(err)
+		}
+	}
+}
+
+func (c *controller) syncPvc(ctx context.Context, key, pvcNamespace, pvcName string) error {
+	if c.populatorNamespace == pvcNamespace {
+		// Ignore PVCs in our own working namespace
+		return nil
+	}
+
+	var err error
+
+	var pvc *corev1.PersistentVolumeClaim
+	pvc, err = c.pvcLister.PersistentVolumeClaims(pvcNamespace).Get(pvcName)
+	if nil != err {
+		if errors.IsNotFound(err) {
+			utilruntime.HandleError(fmt.Errorf("pvc '%s' in work queue no longer exists", key))
+			return nil
+		}
+		return err
+	}
+
+	dataSourceRef := pvc.Spec.DataSourceRef
+	if nil == dataSourceRef {
+		// Ignore PVCs without a datasource
+		return nil
+	}
+
+	if c.gk.Group != *dataSourceRef.APIGroup || c.gk.Kind != dataSourceRef.Kind || "" == dataSourceRef.Name {
+		// Ignore PVCs that aren't for this populator to handle
+		return nil
+	}
+
+	var unstructured *unstructured.Unstructured
+	unstructured, err = c.unstLister.Namespace(pvc.Namespace).Get(dataSourceRef.Name)
+	if nil != err {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		c.addNotification(key, "unstructured", pvc.Namespace, dataSourceRef.Name)
+		// We'll get called again later when the data source exists
+		return nil
+	}
+
+	var waitForFirstConsumer bool
+	var nodeName string
+	if nil != pvc.Spec.StorageClassName {
+		storageClassName := *pvc.Spec.StorageClassName
+
+		var storageClass *storagev1.StorageClass
+		storageClass, err = c.scLister.Get(storageClassName)
+		if nil != err {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			c.addNotification(key, "sc", "", storageClassName)
+			// We'll get called again later when the storage class exists
+			return nil
+		}
+
+		if nil != storageClass.VolumeBindingMode && storagev1.VolumeBindingWaitForFirstConsumer == *storageClass.VolumeBindingMode {
+			waitForFirstConsumer = true
+			nodeName = pvc.Annotations[annSelectedNode]
+			if "" == nodeName {
+				// Wait for the PVC to get a node name before continuing
+				return nil
+			}
+		}
+	}
+
+	// Look for the populator pod
+	podName := fmt.Sprintf("%s-%s", populatorPodPrefix, pvc.UID)
+	c.addNotification(key, "pod", c.populatorNamespace, podName)
+	var pod *corev1.Pod
+	pod, err = c.podLister.Pods(c.populatorNamespace).Get(podName)
+	if nil != err {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// Look for PVC'
+	pvcPrimeName := fmt.Sprintf("%s-%s", populatorPvcPrefix, pvc.UID)
+	c.addNotification(key, "pvc", c.populatorNamespace, pvcPrimeName)
+	var pvcPrime *corev1.PersistentVolumeClaim
+	pvcPrime, err = c.pvcLister.PersistentVolumeClaims(c.populatorNamespace).Get(pvcPrimeName)
+	if nil != err {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// *** Here is the first place we start to create/modify objects ***
+
+	// If the PVC is unbound, we need to perform the population
+	if "" == pvc.Spec.VolumeName {
+
+		// Ensure the PVC has a finalizer on it so we can clean up the stuff we create
+		err = c.ensureFinalizer(ctx, pvc, c.pvcFinalizer, true)
+		if nil != err {
+			return err
+		}
+
+		if nil != pod && corev1.PodFailed == pod.Status.Phase {
+			// Delete failed pods so we can try again
+			err = c.kubeClient.CoreV1().Pods(c.populatorNamespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+			if nil != err {
+				return err
+			}
+			// We'll get called again later when the pod is deleted
+			return nil
+		}
+
+		if nil == pod || corev1.PodSucceeded != pod.Status.Phase {
+			var rawBlock bool
+			if nil != pvc.Spec.VolumeMode && corev1.PersistentVolumeBlock == *pvc.Spec.VolumeMode {
+				rawBlock = true
+			}
+
+			// Get the spec of the populator pod
+			var populatorPod *Pod
+			populatorPod, err = c.populatorPod(rawBlock, unstructured)
+			if nil != err {
+				return err
+			}
+
+			// If the pod doesn't exist yet, create it
+			if nil == pod {
+				pod = &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      podName,
+						Namespace: c.populatorNamespace,
+					},
+					Spec: c.makePopulatePodSpec(&populatorPod.Spec, pvcPrimeName, podName, rawBlock),
+				}
+				if waitForFirstConsumer {
+					pod.Spec.NodeName = nodeName
+				}
+				pod, err = c.kubeClient.CoreV1().Pods(c.populatorNamespace).Create(ctx, pod, metav1.CreateOptions{})
+				if nil != err {
+					return err
+				}
+			}
+
+			// The pod waits for its secret and PVC' to be created. They are
+			// ensured on every sync till the pod succeeds, so that they are
+			// created even if creating them failed after the pod was created.
+			if len(populatorPod.SecretData) > 0 {
+				err = c.ensurePodSecret(ctx, pod, populatorPod.SecretData)
+				if nil != err {
+					return err
+				}
+			}
+
+			// If PVC' doesn't exist yet, create it
+			if nil == pvcPrime {
+				pvcPrime = &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      pvcPrimeName,
+						Namespace: c.populatorNamespace,
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources:        pvc.Spec.Resources,
+						StorageClassName: pvc.Spec.StorageClassName,
+						VolumeMode:       pvc.Spec.VolumeMode,
+					},
+				}
+				if waitForFirstConsumer {
+					pvcPrime.Annotations = map[string]string{
+						annSelectedNode: nodeName,
+					}
+				}
+				_, err = c.kubeClient.CoreV1().PersistentVolumeClaims(c.populatorNamespace).Create(ctx, pvcPrime, metav1.CreateOptions{})
+				if nil != err && !errors.IsAlreadyExists(err) {
+					return err
+				}
+			}
+
+			// We'll get called again later when the pod succeeds
+			return nil
+		}
+
+		// This would be bad
+		if nil == pvcPrime {
+			return fmt.Errorf("Failed to find PVC for populator pod")
+		}
+
+		// Get PV
+		var pv *corev1.PersistentVolume
+		c.addNotification(key, "pv", "", pvcPrime.Spec.VolumeName)
+		pv, err = c.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvcPrime.Spec.VolumeName, metav1.GetOptions{})
+		if nil != err {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			// We'll get called again later when the PV exists
+			return nil
+		}
+
+		// Examine the claimref for the PV and see if it's bound to the correct PVC
+		claimRef := pv.Spec.ClaimRef
+		if claimRef.Name != pvc.Name || claimRef.Namespace != pvc.Namespace || claimRef.UID != pvc.UID {
+			// Make new PV with strategic patch values to perform the PV rebind
+			patchPv := corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        pv.Name,
+					Annotations: map[string]string{},
+				},
+				Spec: corev1.PersistentVolumeSpec{
+					ClaimRef: &corev1.ObjectReference{
+						Namespace:       pvc.Namespace,
+						Name:            pvc.Name,
+						UID:             pvc.UID,
+						ResourceVersion: pvc.ResourceVersion,
+					},
+				},
+			}
+			patchPv.Annotations[c.populatedFromAnno] = pvc.Namespace + "/" + dataSourceRef.Name
+			var patchData []byte
+			patchData, err = json.Marshal(patchPv)
+			if nil != err {
+				return err
+			}
+			_, err = c.kubeClient.CoreV1().PersistentVolumes().Patch(ctx, pv.Name, types.StrategicMergePatchType,
+				patchData, metav1.PatchOptions{})
+			if nil != err {
+				return err
+			}
+
+			// Don't start cleaning up yet -- we need to bind controller to acknowledge
+			// the switch
+			return nil
+		}
+	}
+
+	// Wait for the bind controller to rebind the PV
+	if nil != pvcPrime {
+		if corev1.ClaimLost != pvcPrime.Status.Phase {
+			return nil
+		}
+	}
+
+	// *** At this point the volume population is done and we're just cleaning up ***
+
+	// If the pod still exists, delete it
+	if nil != pod {
+		err = c.kubeClient.CoreV1().Pods(c.populatorNamespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		if nil != err {
+			return err
+		}
+	}
+
+	// If PVC' still exists, delete it
+	if nil != pvcPrime {
+		err = c.kubeClient.CoreV1().PersistentVolumeClaims(c.populatorNamespace).Delete(ctx, pvcPrime.Name, metav1.DeleteOptions{})
+		if nil != err {
+			return err
+		}
+	}
+
+	// Make sure the PVC finalizer is gone
+	err = c.ensureFinalizer(ctx, pvc, c.pvcFinalizer, false)
+	if nil != err {
+		return err
+	}
+
+	// Clean up our internal callback maps
+	c.cleanupNofications(key)
+
+	return nil
+}
+
+// ensurePodSecret creates the secret of the populator pod, it is owned by
+// the pod so that it is deleted with it. The secret of a failed pod which
+// is not deleted yet is taken over by the pod which is created again.
+func (c *controller) ensurePodSecret(ctx context.Context, pod *corev1.Pod, data map[string][]byte) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "Pod",
+					Name:       pod.Name,
+					UID:        pod.UID,
+				},
+			},
+		},
+		Data: data,
+	}
+	_, err := c.kubeClient.CoreV1().Secrets(pod.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if nil == err || !errors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := c.kubeClient.CoreV1().Secrets(pod.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if nil != err {
+		return err
+	}
+	if reflect.DeepEqual(existing.OwnerReferences, secret.OwnerReferences) && reflect.DeepEqual(existing.Data, data) {
+		return nil
+	}
+	existing.OwnerReferences = secret.OwnerReferences
+	existing.Data = data
+	_, err = c.kubeClient.CoreV1().Secrets(pod.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *controller) makePopulatePodSpec(spec *corev1.PodSpec, pvcPrimeName, podName string, rawBlock bool) corev1.PodSpec {
+	podSpec := *spec.DeepCopy()
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+	for i := range podSpec.Volumes {
+		if s := podSpec.Volumes[i].Secret; s != nil && s.SecretName == SecretName {
+			s.SecretName = podName
+		}
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: populatorPodVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcPrimeName,
+			},
+		},
+	})
+	// The volume is used by the init containers too, like the ones copying
+	// the data of each source one after the other
+	containers := []*corev1.Container{}
+	for i := range podSpec.InitContainers {
+		containers = append(containers, &podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		containers = append(containers, &podSpec.Containers[i])
+	}
+	for _, con := range containers {
+		for i := range con.Env {
+			if ref := con.Env[i].ValueFrom; ref != nil && ref.SecretKeyRef != nil && ref.SecretKeyRef.Name == SecretName {
+				ref.SecretKeyRef.Name = podName
+			}
+		}
+		for i := range con.EnvFrom {
+			if ref := con.EnvFrom[i].SecretRef; ref != nil && ref.Name == SecretName {
+				ref.Name = podName
+			}
+		}
+		if "" == con.ImagePullPolicy {
+			con.ImagePullPolicy = corev1.PullIfNotPresent
+		}
+		if rawBlock {
+			con.VolumeDevices = append(con.VolumeDevices, corev1.VolumeDevice{
+				Name:       populatorPodVolumeName,
+				DevicePath: c.devicePath,
+			})
+		} else {
+			con.VolumeMounts = append(con.VolumeMounts, corev1.VolumeMount{
+				Name:      populatorPodVolumeName,
+				MountPath: c.mountPath,
+			})
+		}
+	}
+	return podSpec
+}
+
+func (c *controller) ensureFinalizer(ctx context.Context, pvc *corev1.PersistentVolumeClaim, finalizer string, want bool) error {
+	finalizers := pvc.GetFinalizers()
+	found := false
+	foundIdx := -1
+	for i, v := range finalizers {
+		if finalizer == v {
+			found = true
+			foundIdx = i
+			break
+		}
+	}
+	if found == want {
+		// Nothing to do in this case
+		return nil
+	}
+
+	type patchOp struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value,omitempty"`
+	}
+
+	var patch []patchOp
+
+	if want {
+		// Add the finalizer to the end of the list
+		patch = []patchOp{
+			{
+				Op:    "test",
+				Path:  "/metadata/finalizers",
+				Value: finalizers,
+			},
+			{
+				Op:    "add",
+				Path:  "/metadata/finalizers/-",
+				Value: finalizer,
+			},
+		}
+	} else {
+		// Remove the finalizer from the list index where it was found
+		path := fmt.Sprintf("/metadata/finalizers/%d", foundIdx)
+		patch = []patchOp{
+			{
+				Op:    "test",
+				Path:  path,
+				Value: finalizer,
+			},
+			{
+				Op:   "remove",
+				Path: path,
+			},
+		}
+	}
+
+	data, err := json.Marshal(patch)
+	if nil != err {
+		return err
+	}
+	_, err = c.kubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.JSONPatchType,
+		data, metav1.PatchOptions{})
+	if nil != err {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package populator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamiclister"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+const testNamespace = "populator"
+
+var (
+	testGK  = schema.GroupKind{Group: "openebs.io", Kind: "TestPopulator"}
+	testGVR = schema.GroupVersionResource{Group: "openebs.io", Version: "v1alpha1", Resource: "testpopulators"}
+)
+
+// newTestController returns a controller whose listers have the given
+// objects, the fake client has them too. The returned func updates the pvc
+// and pod listers from the client, like the informers do after a sync.
+func newTestController(t *testing.T, objects ...runtime.Object) (*controller, *fake.Clientset, func()) {
+	client := fake.NewSimpleClientset(objects...)
+	indexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	pvcs, pods, scs, unsts := indexer(), indexer(), indexer(), indexer()
+	for _, obj := range objects {
+		var err error
+		switch obj.(type) {
+		case *corev1.PersistentVolumeClaim:
+			err = pvcs.Add(obj)
+		case *corev1.Pod:
+			err = pods.Add(obj)
+		case *storagev1.StorageClass:
+			err = scs.Add(obj)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	source := &unstructured.Unstructured{}
+	source.SetAPIVersion(testGVR.GroupVersion().String())
+	source.SetKind(testGK.Kind)
+	source.SetNamespace("default")
+	source.SetName("source")
+	if err := unsts.Add(source); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &controller{
+		kubeClient:         client,
+		populatorNamespace: testNamespace,
+		mountPath:          "/mnt",
+		populatedFromAnno:  "openebs.io/" + populatedFromAnnoSuffix,
+		pvcFinalizer:       "openebs.io/" + pvcFinalizerSuffix,
+		pvcLister:          corelisters.NewPersistentVolumeClaimLister(pvcs),
+		podLister:          corelisters.NewPodLister(pods),
+		scLister:           storagelisters.NewStorageClassLister(scs),
+		unstLister:         dynamiclister.New(unsts, testGVR),
+		notifyMap:          make(map[string]*stringSet),
+		cleanupMap:         make(map[string]*stringSet),
+		gk:                 testGK,
+		populatorPod: func(rawBlock bool, u *unstructured.Unstructured) (*Pod, error) {
+			return &Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: ContainerName, Image: "populator"}},
+				},
+				SecretData: map[string][]byte{"password": []byte("secret")},
+			}, nil
+		},
+	}
+	resync := func() {
+		pvcList, err := client.CoreV1().PersistentVolumeClaims("").List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		podList, err := client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range pvcList.Items {
+			if err := pvcs.Update(&pvcList.Items[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i := range podList.Items {
+			if err := pods.Update(&podList.Items[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return c, client, resync
+}
+
+func newTestPVC() *corev1.PersistentVolumeClaim {
+	storageClass := "standard"
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "data",
+			UID:        "uid",
+			Finalizers: []string{"kubernetes.io/pvc-protection"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+			DataSourceRef: &corev1.TypedLocalObjectReference{
+				APIGroup: &testGK.Group,
+				Kind:     testGK.Kind,
+				Name:     "source",
+			},
+		},
+	}
+}
+
+func TestSyncPvcEnsuresSecretAndPVCPrime(t *testing.T) {
+	pvc := newTestPVC()
+	sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}
+	podName := populatorPodPrefix + "-" + string(pvc.UID)
+	pvcPrimeName := populatorPvcPrefix + "-" + string(pvc.UID)
+	pendingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: podName, UID: "pod-uid"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+
+	tests := map[string]struct {
+		// objects are in the listers and the client before the sync
+		objects []runtime.Object
+		// failCreate fails the first create of the resource
+		failCreate string
+		// syncs is the number of syncs, the pod created by a sync is
+		// added to the pod lister before the next one
+		syncs int
+	}{
+		"pod, secret and pvc' are created": {
+			objects: []runtime.Object{pvc, sc},
+			syncs:   1,
+		},
+		"secret is created again after it failed": {
+			objects:    []runtime.Object{pvc, sc},
+			failCreate: "secrets",
+			syncs:      2,
+		},
+		"pvc' is created again after it failed": {
+			objects:    []runtime.Object{pvc, sc},
+			failCreate: "persistentvolumeclaims",
+			syncs:      2,
+		},
+		"secret and pvc' of a pending pod are created": {
+			objects: []runtime.Object{pvc, sc, pendingPod},
+			syncs:   1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, client, resync := newTestController(t, test.objects...)
+			if test.failCreate != "" {
+				failed := false
+				client.PrependReactor("create", test.failCreate, func(k8stesting.Action) (bool, runtime.Object, error) {
+					if failed {
+						return false, nil, nil
+					}
+					failed = true
+					return true, nil, fmt.Errorf("transient error")
+				})
+			}
+
+			ctx := context.TODO()
+			for i := 0; i < test.syncs; i++ {
+				err := c.syncPvc(ctx, "default/data", pvc.Namespace, pvc.Name)
+				if test.failCreate != "" && i == 0 {
+					if err == nil {
+						t.Fatalf("sync %d: want the transient error", i)
+					}
+				} else if err != nil {
+					t.Fatalf("sync %d: %v", i, err)
+				}
+				resync()
+			}
+
+			secret, err := client.CoreV1().Secrets(testNamespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("getting the secret of the populator pod: %v", err)
+			}
+			if string(secret.Data["password"]) != "secret" {
+				t.Errorf("secret data = %v, want the data of the populator pod", secret.Data)
+			}
+			if _, err := client.CoreV1().PersistentVolumeClaims(testNamespace).
+				Get(ctx, pvcPrimeName, metav1.GetOptions{}); err != nil {
+				t.Errorf("getting pvc': %v", err)
+			}
+		})
+	}
+}
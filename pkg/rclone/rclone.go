@@ -0,0 +1,35 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rclone
+
+// FilterArgs returns the rclone filter rules for the given patterns.
+// Rules are matched in order, so the excludes come first to take
+// precedence over the includes. If any include is given, everything
+// else is excluded at the end.
+func FilterArgs(include, exclude []string) []string {
+	args := []string{}
+	for _, e := range exclude {
+		args = append(args, "--filter", "- "+e)
+	}
+	for _, i := range include {
+		args = append(args, "--filter", "+ "+i)
+	}
+	if len(include) > 0 {
+		args = append(args, "--filter", "- **")
+	}
+	return args
+}
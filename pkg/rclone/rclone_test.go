@@ -0,0 +1,50 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rclone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterArgs(t *testing.T) {
+	tests := map[string]struct {
+		include []string
+		exclude []string
+		want    []string
+	}{
+		"no patterns": {
+			want: []string{},
+		},
+		"only excludes": {
+			exclude: []string{"*.tmp"},
+			want:    []string{"--filter", "- *.tmp"},
+		},
+		"excludes come before includes": {
+			include: []string{"*.log"},
+			exclude: []string{"debug.log"},
+			want:    []string{"--filter", "- debug.log", "--filter", "+ *.log", "--filter", "- **"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := FilterArgs(test.include, test.exclude); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FilterArgs() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
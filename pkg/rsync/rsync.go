@@ -0,0 +1,92 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rsync
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+// Args returns the rsync args of the behaviours set in the options, the
+// file metadata is preserved by default. The modification times are always
+// preserved, so that the files which are already copied are skipped.
+func Args(options *internalv1alpha1.RsyncOptions) []string {
+	if options == nil {
+		options = &internalv1alpha1.RsyncOptions{}
+	}
+
+	args := []string{"--recursive", "--times"}
+	if isSet(options.Archive) {
+		// The owners are kept by their ids, the populator pods don't have
+		// the users and groups of the source
+		args = append(args, "--archive", "--numeric-ids")
+	}
+	for _, option := range []struct {
+		set bool
+		arg string
+	}{
+		{isSet(options.HardLinks), "--hard-links"},
+		{isSet(options.ACLs), "--acls"},
+		{isSet(options.Xattrs), "--xattrs"},
+		{options.Sparse, "--sparse"},
+		{options.Compress, "--compress"},
+		{options.Checksum, "--checksum"},
+		{options.Delete, "--delete"},
+	} {
+		if option.set {
+			args = append(args, option.arg)
+		}
+	}
+	return args
+}
+
+// isSet returns the value of an option which defaults to true
+func isSet(option *bool) bool {
+	return option == nil || *option
+}
+
+// FilterArgs returns the rsync filter rules for the given patterns. The
+// rules are matched in order, so the excludes come first to take precedence
+// over the includes. If any include is given, the directories are searched
+// for the matching files and everything else is excluded at the end.
+func FilterArgs(include, exclude []string) []string {
+	args := []string{}
+	for _, e := range exclude {
+		args = append(args, "--exclude="+e)
+	}
+	for _, i := range include {
+		args = append(args, "--include="+i)
+	}
+	if len(include) > 0 {
+		args = append(args, "--include=*/", "--exclude=*", "--prune-empty-dirs")
+	}
+	return args
+}
+
+// CleanSubPath returns the sub path relative to the root of a volume. It is
+// cleaned as absolute so that it doesn't go out of the volume, and it is an
+// error if nothing is left of a sub path which is set.
+func CleanSubPath(subPath string) (string, error) {
+	cleaned := strings.Trim(path.Clean("/"+subPath), "/")
+	if subPath != "" && cleaned == "" {
+		return "", fmt.Errorf("`%s` is not inside the volume", subPath)
+	}
+	return cleaned, nil
+}
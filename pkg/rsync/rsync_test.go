@@ -0,0 +1,124 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rsync
+
+import (
+	"reflect"
+	"testing"
+
+	internalv1alpha1 "github.com/openebs/data-populator/apis/openebs.io/v1alpha1"
+)
+
+func TestArgs(t *testing.T) {
+	unset := false
+	tests := map[string]struct {
+		options *internalv1alpha1.RsyncOptions
+		want    []string
+	}{
+		"nil options keep the file metadata": {
+			options: nil,
+			want: []string{"--recursive", "--times", "--archive", "--numeric-ids",
+				"--hard-links", "--acls", "--xattrs"},
+		},
+		"unset metadata options": {
+			options: &internalv1alpha1.RsyncOptions{
+				Archive:   &unset,
+				HardLinks: &unset,
+				ACLs:      &unset,
+				Xattrs:    &unset,
+			},
+			want: []string{"--recursive", "--times"},
+		},
+		"all the optional behaviours": {
+			options: &internalv1alpha1.RsyncOptions{
+				Archive:   &unset,
+				HardLinks: &unset,
+				ACLs:      &unset,
+				Xattrs:    &unset,
+				Sparse:    true,
+				Compress:  true,
+				Checksum:  true,
+				Delete:    true,
+			},
+			want: []string{"--recursive", "--times", "--sparse", "--compress", "--checksum", "--delete"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Args(test.options); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Args() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFilterArgs(t *testing.T) {
+	tests := map[string]struct {
+		include []string
+		exclude []string
+		want    []string
+	}{
+		"no patterns": {
+			want: []string{},
+		},
+		"only excludes": {
+			exclude: []string{"*.tmp", "cache/"},
+			want:    []string{"--exclude=*.tmp", "--exclude=cache/"},
+		},
+		"excludes come before includes": {
+			include: []string{"*.log"},
+			exclude: []string{"debug.log"},
+			want: []string{"--exclude=debug.log", "--include=*.log",
+				"--include=*/", "--exclude=*", "--prune-empty-dirs"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := FilterArgs(test.include, test.exclude); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FilterArgs() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCleanSubPath(t *testing.T) {
+	tests := map[string]struct {
+		subPath string
+		want    string
+		wantErr bool
+	}{
+		"empty":              {subPath: "", want: ""},
+		"relative":           {subPath: "data/db", want: "data/db"},
+		"absolute":           {subPath: "/data/db/", want: "data/db"},
+		"parent in the path": {subPath: "data/../db", want: "db"},
+		"out of the volume":  {subPath: "../../etc", want: "etc"},
+		"root of the volume": {subPath: "/", wantErr: true},
+		"parent of the root": {subPath: "..", wantErr: true},
+		"current directory":  {subPath: ".", wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := CleanSubPath(test.subPath)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("CleanSubPath(%q) error = %v, wantErr %t", test.subPath, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("CleanSubPath(%q) = %q, want %q", test.subPath, got, test.want)
+			}
+		})
+	}
+}
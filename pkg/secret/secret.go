@@ -0,0 +1,49 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Get returns the data of the secret `name` in `namespace`. It returns an
+// error if any of the required keys is missing from the secret.
+func Get(client kubernetes.Interface, namespace, name string, required ...string) (map[string]string, error) {
+	secret, err := client.CoreV1().Secrets(namespace).
+		Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting secret `%s` in `%s` namespace error: %s",
+			name, namespace, err)
+	}
+
+	data := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+
+	for _, k := range required {
+		if _, ok := data[k]; !ok {
+			return nil, fmt.Errorf("secret `%s` in `%s` namespace does not have the `%s` key",
+				name, namespace, k)
+		}
+	}
+	return data, nil
+}
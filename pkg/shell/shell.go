@@ -0,0 +1,64 @@
+/*
+Copyright © 2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shell
+
+import (
+	"strings"
+)
+
+// Quote returns s quoted so that bash treats it as a single word.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Script is used to build the bash script that is run by the populator pod.
+type Script struct {
+	lines []string
+}
+
+// Export adds a statement exporting the given environment variable.
+func (s *Script) Export(name, value string) *Script {
+	s.lines = append(s.lines, "export "+name+"="+Quote(value))
+	return s
+}
+
+// Run adds a command to the script, every word is quoted.
+func (s *Script) Run(words ...string) *Script {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		quoted = append(quoted, Quote(w))
+	}
+	s.lines = append(s.lines, strings.Join(quoted, " "))
+	return s
+}
+
+// Raw adds a statement to the script as it is. It should only be
+// used for statements which do not contain any user input.
+func (s *Script) Raw(statement string) *Script {
+	s.lines = append(s.lines, statement)
+	return s
+}
+
+// Args returns the arguments for the populator container. The script
+// exits on the first failing command.
+func (s *Script) Args() []string {
+	return []string{
+		"bash",
+		"-c",
+		strings.Join(append([]string{"set -eo pipefail"}, s.lines...), "\n"),
+	}
+}